@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Maksim646/sunny_5_skiers/internal/controller"
+	"go.uber.org/zap"
+)
+
+// runDraw assigns start times to the registered competitors and writes them
+// as event 2 lines.
+func runDraw(args []string) error {
+	flags := flag.NewFlagSet("draw", flag.ContinueOnError)
+	configPath := flags.String("config", cfg.ConfigPath, "config of the race")
+	eventsPath := flags.String("events", cfg.EventsPath, "events with the registrations (event 1)")
+	strategy := flags.String("strategy", controller.DrawRandom, "draw strategy: random, groups or bib")
+	seed := flags.Int64("seed", time.Now().UnixNano(), "seed of the random draw")
+	groupSize := flags.Int("group-size", 10, "competitors in a seeding group for the groups strategy")
+	drawAtRaw := flags.String("draw-at", "", "time of the draw events, the last registration by default")
+	outPath := flags.String("out", stdioPath, "output file, - for stdout")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *configPath == stdioPath && *eventsPath == stdioPath {
+		return fmt.Errorf("config and events cannot both be read from stdin")
+	}
+
+	parsedConfig, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	events, err := loadEvents(*eventsPath)
+	if err != nil {
+		return err
+	}
+
+	options := controller.DrawOptions{Strategy: *strategy, Seed: *seed, GroupSize: *groupSize}
+	if *drawAtRaw != "" {
+		if options.At, err = time.Parse(cfg.TimeFormat, *drawAtRaw); err != nil {
+			return err
+		}
+	}
+
+	startList, err := controller.Draw(events, parsedConfig, options, cfg.TimeFormat)
+	if err != nil {
+		return err
+	}
+	zap.L().Info("start draw", zap.String("strategy", *strategy), zap.Int64("seed", *seed))
+
+	return writeOutput(*outPath, func(w io.Writer) error {
+		return controller.WriteEvents(w, startList, cfg.TimeFormat)
+	})
+}
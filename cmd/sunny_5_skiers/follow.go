@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/Maksim646/sunny_5_skiers/internal/controller"
+)
+
+// runFollow follows the events file during a race and keeps the output log
+// and the result table up to date until interrupted.
+func runFollow(args []string) error {
+	flags := flag.NewFlagSet("follow", flag.ContinueOnError)
+	interval := flags.Duration("interval", 500*time.Millisecond, "how often to check the events file")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	// The events file is tailed and the outputs are rewritten, which stdin and
+	// stdout cannot do.
+	for _, path := range []string{cfg.EventsPath, cfg.OutputFilePath, cfg.ResultTablePath} {
+		if path == stdioPath {
+			return errors.New("follow needs files for the events and the outputs, - is not supported")
+		}
+	}
+
+	parsedConfig, err := loadConfig(cfg.ConfigPath)
+	if err != nil {
+		return err
+	}
+
+	follower, err := controller.NewFollower(parsedConfig, controller.FollowOptions{
+		EventsPath:            cfg.EventsPath,
+		OutputFilePath:        cfg.OutputFilePath,
+		ResultTablePath:       cfg.ResultTablePath,
+		TimeFormat:            cfg.TimeFormat,
+		ReportTableTimeFormat: cfg.ReportTableTimeFormat,
+		OutputFormat:          cfg.OutputFormat,
+		TargetsInFireLine:     cfg.TargetsInFireLine,
+		Strict:                cfg.StrictValidation,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return controller.Follow(ctx, follower, *interval)
+}
@@ -1,8 +1,17 @@
 package main
 
 import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
 	"github.com/Maksim646/sunny_5_skiers/config"
 	"github.com/Maksim646/sunny_5_skiers/internal/controller"
+	"github.com/Maksim646/sunny_5_skiers/internal/durationfmt"
+	"github.com/Maksim646/sunny_5_skiers/internal/output"
+	"github.com/Maksim646/sunny_5_skiers/internal/race"
+	"github.com/Maksim646/sunny_5_skiers/model"
 	logger "github.com/Maksim646/sunny_5_skiers/pkg"
 	"github.com/kelseyhightower/envconfig"
 	"go.uber.org/zap"
@@ -17,23 +26,149 @@ func main() {
 	defer zap.L().Sync()
 	zap.L().Info("Logger started")
 
-	parsedConfig, err := controller.ParseConfig(cfg.ConfigPath, cfg.TimeFormat, cfg.TimeDurationFormat)
+	command, args := "run", os.Args[1:]
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+
+	var err error
+	switch command {
+	case "run":
+		run()
+	case "pursuit":
+		err = runPursuit(args)
+	case "draw":
+		err = runDraw(args)
+	case "follow":
+		err = runFollow(args)
+	case "serve":
+		err = runServe(args)
+	default:
+		zap.L().Error("unknown command", zap.String("command", command))
+	}
+	if err != nil {
+		zap.L().Error("error run "+command, zap.Error(err))
+	}
+}
+
+func run() {
+	if cfg.ConfigPath == stdioPath && cfg.EventsPath == stdioPath {
+		zap.L().Error("config and events cannot both be read from stdin")
+		return
+	}
+
+	// The formats are checked before any output file is opened, so a bad
+	// setting leaves the previous outputs in place.
+	if !slices.Contains(output.Formats, cfg.OutputFormat) {
+		zap.L().Error("unknown output format", zap.String("format", cfg.OutputFormat))
+		return
+	}
+	logFormat := output.LogFormatFor(cfg.OutputFormat)
+	if _, err := durationfmt.Compile(cfg.ReportTableTimeFormat); err != nil {
+		zap.L().Error("error report table time format", zap.Error(err))
+		return
+	}
+
+	parsedConfig, err := loadConfig(cfg.ConfigPath)
 	if err != nil {
 		zap.L().Error("error load config", zap.Error(err))
+		return
 	}
 
-	events, err := controller.ParseEvents(cfg.EventsPath, cfg.TimeFormat)
+	events, err := loadEvents(cfg.EventsPath)
 	if err != nil {
 		zap.L().Error("error parse events", zap.Error(err))
+		return
 	}
+	events = controller.PlaceEvents(events, parsedConfig.Date)
 
-	err = controller.ProcessEvents(events, cfg.OutputFilePath, cfg.TimeFormat)
+	err = controller.ValidateSequence(events, parsedConfig, cfg.TimeFormat, cfg.TargetsInFireLine, cfg.StrictValidation)
 	if err != nil {
-		zap.L().Error("error process events", zap.Error(err))
+		zap.L().Error("error validate events", zap.Error(err))
+		return
 	}
 
-	err = controller.GenerateResultingTable(events, cfg.ResultTablePath, cfg.ReportTableTimeFormat, parsedConfig, cfg.TargetsInFireLine)
+	engine := race.NewEngine(parsedConfig, cfg.TimeFormat, cfg.TargetsInFireLine)
+
+	err = writeOutput(cfg.OutputFilePath, func(w io.Writer) error {
+		return controller.ProcessEventsTo(w, engine, events, logFormat)
+	})
 	if err != nil {
 		zap.L().Error("error process events", zap.Error(err))
+		return
+	}
+
+	err = writeOutput(cfg.ResultTablePath, func(w io.Writer) error {
+		return controller.WriteResultingTable(w, engine, cfg.OutputFormat, cfg.ReportTableTimeFormat)
+	})
+	if err != nil {
+		zap.L().Error("error generate result table", zap.Error(err))
 	}
 }
+
+// loadRace replays a finished race and returns its engine.
+func loadRace(configPath string, eventsPath string) (*race.Engine, error) {
+	parsedConfig, err := loadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := loadEvents(eventsPath)
+	if err != nil {
+		return nil, err
+	}
+	events = controller.PlaceEvents(events, parsedConfig.Date)
+
+	engine := race.NewEngine(parsedConfig, cfg.TimeFormat, cfg.TargetsInFireLine)
+	for _, event := range events {
+		if _, err := engine.Apply(event); err != nil {
+			return nil, err
+		}
+	}
+	engine.Finish()
+
+	return engine, nil
+}
+
+// loadConfig parses the race config together with the roster of ROSTER_PATH, if set.
+func loadConfig(configPath string) (model.Config, error) {
+	parsedConfig, err := parseConfig(configPath)
+	if err != nil {
+		return parsedConfig, err
+	}
+
+	if cfg.RosterPath != "" {
+		parsedConfig.Roster, err = controller.ParseRoster(cfg.RosterPath)
+		if err != nil {
+			return parsedConfig, err
+		}
+	}
+
+	return parsedConfig, nil
+}
+
+// parseConfig parses the race config of configPath, "-" reads stdin.
+func parseConfig(configPath string) (model.Config, error) {
+	input, err := openInput(configPath)
+	if err != nil {
+		return model.Config{}, err
+	}
+	defer input.Close()
+
+	parsedConfig, err := controller.ReadConfig(input, cfg.TimeFormat, cfg.TimeDurationFormat)
+	if err != nil {
+		return parsedConfig, fmt.Errorf("%s: %w", inputName(configPath), err)
+	}
+	return parsedConfig, nil
+}
+
+// loadEvents parses the events of eventsPath, "-" reads stdin.
+func loadEvents(eventsPath string) ([]model.CompetitorEvent, error) {
+	input, err := openInput(eventsPath)
+	if err != nil {
+		return nil, err
+	}
+	defer input.Close()
+
+	return controller.ReadEvents(input, inputName(eventsPath), cfg.TimeFormat)
+}
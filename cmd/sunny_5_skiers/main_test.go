@@ -0,0 +1,143 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Maksim646/sunny_5_skiers/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunKeepsOutputsOnBadInput(t *testing.T) {
+	validConfig := `{"laps": 1, "lapLen": 3000, "penaltyLen": 150, "firingLines": 1, "start": "10:00:00.000", "startDelta": "00:01:00"}`
+	validEvents := "[09:50:00.000] 1 1\n"
+
+	tests := []struct {
+		name        string
+		config      string
+		events      string
+		format      string
+		tableFormat string
+	}{
+		{"MalformedEvents", validConfig, "[09:50:00.000] 1 1\n[09:51] 2 1 10:00:00.000\n", "text", "hh:mm:ss.fff"},
+		{"UnknownEvent", validConfig, "[09:50:00.000] 99 1\n", "text", "hh:mm:ss.fff"},
+		{"InvalidConfig", `{"laps": 1, "start": "soon"}`, validEvents, "text", "hh:mm:ss.fff"},
+		{"UnknownFormat", validConfig, validEvents, "xml", "hh:mm:ss.fff"},
+		{"InvalidTableTimeFormat", validConfig, validEvents, "text", "hh:ss"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			write := func(name string, content string) string {
+				path := filepath.Join(dir, name)
+				require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+				return path
+			}
+
+			previous := cfg
+			defer func() { cfg = previous }()
+			cfg = config.Config{
+				ConfigPath:            write("config.json", tt.config),
+				EventsPath:            write("events", tt.events),
+				OutputFilePath:        write("output_events_log.txt", "previous log\n"),
+				ResultTablePath:       write("result_table.txt", "previous table\n"),
+				TimeFormat:            "15:04:05.000",
+				TimeDurationFormat:    "15:04:05",
+				ReportTableTimeFormat: tt.tableFormat,
+				OutputFormat:          tt.format,
+				TargetsInFireLine:     5,
+			}
+
+			run()
+
+			outputLog, err := os.ReadFile(cfg.OutputFilePath)
+			require.NoError(t, err)
+			assert.Equal(t, "previous log\n", string(outputLog))
+
+			resultTable, err := os.ReadFile(cfg.ResultTablePath)
+			require.NoError(t, err)
+			assert.Equal(t, "previous table\n", string(resultTable))
+		})
+	}
+}
+
+func TestRunHTML(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.json")
+	require.NoError(t, os.WriteFile(configPath, []byte(`{"laps": 1, "lapLen": 3000, "penaltyLen": 150, "firingLines": 1, "start": "10:00:00.000", "startDelta": "00:01:00"}`), 0644))
+	eventsPath := filepath.Join(dir, "events")
+	require.NoError(t, os.WriteFile(eventsPath, []byte(
+		"[09:50:00.000] 1 1\n[09:51:00.000] 2 1 10:00:00.000\n[09:59:00.000] 3 1\n[10:00:00.000] 4 1\n[10:05:00.000] 10 1\n"), 0644))
+
+	previous := cfg
+	defer func() { cfg = previous }()
+	cfg = config.Config{
+		ConfigPath:            configPath,
+		EventsPath:            eventsPath,
+		OutputFilePath:        filepath.Join(dir, "output_events_log.txt"),
+		ResultTablePath:       filepath.Join(dir, "result_table.html"),
+		TimeFormat:            "15:04:05.000",
+		TimeDurationFormat:    "15:04:05",
+		ReportTableTimeFormat: "hh:mm:ss.fff",
+		OutputFormat:          "html",
+		TargetsInFireLine:     5,
+	}
+
+	run()
+
+	outputLog, err := os.ReadFile(cfg.OutputFilePath)
+	require.NoError(t, err)
+	assert.Contains(t, string(outputLog), "[10:05:00.000] The competitor(1) has finished\n")
+
+	resultTable, err := os.ReadFile(cfg.ResultTablePath)
+	require.NoError(t, err)
+	assert.Contains(t, string(resultTable), "<!DOCTYPE html>")
+	assert.Contains(t, string(resultTable), "<td>00:05:00.000</td>")
+}
+
+func TestFollowRejectsStdio(t *testing.T) {
+	previous := cfg
+	defer func() { cfg = previous }()
+	cfg = config.Config{
+		ConfigPath:      "config.json",
+		EventsPath:      stdioPath,
+		OutputFilePath:  "output_events_log.txt",
+		ResultTablePath: "result_table.txt",
+	}
+
+	err := runFollow(nil)
+	assert.EqualError(t, err, "follow needs files for the events and the outputs, - is not supported")
+	assert.NoFileExists(t, stdioPath)
+
+	err = runServe([]string{"-events", stdioPath})
+	assert.EqualError(t, err, "serve reads the events from a file, - is not supported")
+}
+
+func TestPursuitFromResultTable(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.json")
+	require.NoError(t, os.WriteFile(configPath, []byte(`{"laps": 1, "lapLen": 3000, "penaltyLen": 150, "firingLines": 1, "start": "10:00:00.000", "startDelta": "00:01:00"}`), 0644))
+	resultsPath := filepath.Join(dir, "result_table.txt")
+	require.NoError(t, os.WriteFile(resultsPath, []byte(
+		"[00:05:00.000] 2 [{00:05:00.000, 10.000}] {,} 5/5 []\n"+
+			"[00:05:12.500] 1 +00:00:12.500 [{00:05:12.500, 9.600}] {,} 4/5 []\n"+
+			"[NotFinished] 3 [{,}] {,} 0/0 []\n"), 0644))
+	outPath := filepath.Join(dir, "pursuit_events")
+
+	previous := cfg
+	defer func() { cfg = previous }()
+	cfg = config.Config{
+		TimeFormat:            "15:04:05.000",
+		TimeDurationFormat:    "15:04:05",
+		ReportTableTimeFormat: "hh:mm:ss.fff",
+	}
+
+	require.NoError(t, runPursuit([]string{"-config", configPath, "-results", resultsPath, "-start", "11:00:00.000", "-out", outPath}))
+
+	startList, err := os.ReadFile(outPath)
+	require.NoError(t, err)
+	assert.Equal(t, "[10:30:00.000] 2 2 11:00:00.000\n[10:30:00.000] 2 1 11:00:12.500\n", string(startList))
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"time"
+
+	"github.com/Maksim646/sunny_5_skiers/internal/controller"
+	"github.com/Maksim646/sunny_5_skiers/model"
+)
+
+// runPursuit writes the start list of a pursuit from the results of a
+// previous race as event 2 lines. The results are replayed from the events
+// of the race or read from a saved text result table.
+func runPursuit(args []string) error {
+	flags := flag.NewFlagSet("pursuit", flag.ContinueOnError)
+	configPath := flags.String("config", cfg.ConfigPath, "config of the previous race")
+	eventsPath := flags.String("events", cfg.EventsPath, "events of the previous race")
+	resultsPath := flags.String("results", "", "text result table of the previous race, used instead of -events")
+	startRaw := flags.String("start", "", "start of the pursuit, the start of the previous race by default")
+	drawAtRaw := flags.String("draw-at", "", "time of the draw events, 30 minutes before the start by default")
+	maxGapRaw := flags.String("max-gap", "", "largest start gap to the leader, e.g. 00:03:00")
+	outPath := flags.String("out", stdioPath, "output file, - for stdout")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	reports, start, err := loadResults(*configPath, *eventsPath, *resultsPath)
+	if err != nil {
+		return err
+	}
+
+	if *startRaw != "" {
+		if start, err = time.Parse(cfg.TimeFormat, *startRaw); err != nil {
+			return err
+		}
+	}
+
+	drawAt := start.Add(-30 * time.Minute)
+	if *drawAtRaw != "" {
+		if drawAt, err = time.Parse(cfg.TimeFormat, *drawAtRaw); err != nil {
+			return err
+		}
+	}
+
+	var maxGap time.Duration
+	if *maxGapRaw != "" {
+		if maxGap, err = controller.ParseClockDuration(*maxGapRaw, cfg.TimeDurationFormat); err != nil {
+			return err
+		}
+	}
+
+	startList := controller.PursuitStartList(reports, start, drawAt, maxGap, cfg.TimeFormat)
+
+	return writeOutput(*outPath, func(w io.Writer) error {
+		return controller.WriteEvents(w, startList, cfg.TimeFormat)
+	})
+}
+
+// loadResults returns the sorted reports of the previous race and its start,
+// read from the result table of resultsPath when set, "-" reads stdin.
+func loadResults(configPath string, eventsPath string, resultsPath string) ([]model.CompetitorReport, time.Time, error) {
+	if resultsPath == "" {
+		engine, err := loadRace(configPath, eventsPath)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		return engine.Reports(), engine.Config().Start, nil
+	}
+
+	if configPath == stdioPath && resultsPath == stdioPath {
+		return nil, time.Time{}, errors.New("config and results cannot both be read from stdin")
+	}
+
+	parsedConfig, err := parseConfig(configPath)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	input, err := openInput(resultsPath)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	defer input.Close()
+
+	reports, err := controller.ReadResultTable(input, inputName(resultsPath), cfg.ReportTableTimeFormat)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return reports, parsedConfig.Start, nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/Maksim646/sunny_5_skiers/internal/controller"
+	"github.com/Maksim646/sunny_5_skiers/internal/server"
+	"go.uber.org/zap"
+)
+
+// runServe serves the state of a race over HTTP. The events file is read on
+// start and, with -follow, followed like in the follow command.
+func runServe(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := flags.String("addr", ":8080", "address to listen on")
+	eventsPath := flags.String("events", cfg.EventsPath, "events file to load, empty to start with no events")
+	follow := flags.Bool("follow", false, "keep reading events appended to the events file")
+	interval := flags.Duration("interval", 500*time.Millisecond, "how often to check the events file")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *eventsPath == stdioPath {
+		return errors.New("serve reads the events from a file, - is not supported")
+	}
+
+	parsedConfig, err := loadConfig(cfg.ConfigPath)
+	if err != nil {
+		return err
+	}
+
+	srv := server.New(parsedConfig, cfg.TimeFormat, cfg.TargetsInFireLine, cfg.StrictValidation)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if *eventsPath != "" {
+		tail := controller.NewTail(*eventsPath)
+		if err := applyNewLines(srv, tail); err != nil {
+			return err
+		}
+		if *follow {
+			go func() {
+				ticker := time.NewTicker(*interval)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+						if err := applyNewLines(srv, tail); err != nil {
+							zap.L().Error("error follow events", zap.Error(err))
+						}
+					}
+				}
+			}()
+		}
+	}
+
+	httpServer := &http.Server{Addr: *addr, Handler: srv.Handler()}
+	httpServer.RegisterOnShutdown(srv.Close)
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = httpServer.Shutdown(shutdownCtx)
+	}()
+
+	zap.L().Info("serving race", zap.String("addr", *addr))
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func applyNewLines(srv *server.Server, tail *controller.Tail) error {
+	lines, truncated, err := tail.Poll()
+	if err != nil {
+		return err
+	}
+	if truncated {
+		zap.L().Warn("events file truncated, new lines are applied to the running race")
+	}
+
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		event, err := controller.ParseEventLine(line, cfg.TimeFormat)
+		if err != nil {
+			zap.L().Warn("skip invalid event", zap.Error(err))
+			continue
+		}
+		if _, err := srv.Apply(event); err != nil {
+			zap.L().Warn("skip event", zap.Error(err))
+		}
+	}
+	return nil
+}
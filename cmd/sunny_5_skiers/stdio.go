@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+
+	"github.com/Maksim646/sunny_5_skiers/internal/controller"
+)
+
+// stdioPath stands for stdin as an input path and for stdout as an output path.
+const stdioPath = "-"
+
+// openInput opens the file at path, or stdin for "-".
+func openInput(path string) (io.ReadCloser, error) {
+	if path == stdioPath {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(path)
+}
+
+// writeOutput writes to the file at path, or to stdout for "-", through a buffer.
+func writeOutput(path string, write func(w io.Writer) error) error {
+	if path != stdioPath {
+		return controller.WriteFile(path, write)
+	}
+
+	writer := bufio.NewWriter(os.Stdout)
+	if err := write(writer); err != nil {
+		return err
+	}
+	return writer.Flush()
+}
+
+// inputName is the name of the input in parse errors.
+func inputName(path string) string {
+	if path == stdioPath {
+		return "stdin"
+	}
+	return path
+}
@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math/rand"
+	"slices"
+	"time"
+
+	"github.com/Maksim646/sunny_5_skiers/model"
+)
+
+const (
+	DrawRandom       = "random"
+	DrawSeededGroups = "groups"
+	DrawBibOrder     = "bib"
+)
+
+type DrawOptions struct {
+	Strategy string
+	// Seed makes a random draw reproducible.
+	Seed int64
+	// GroupSize is the number of competitors in a seeding group.
+	GroupSize int
+	// At is the time of the draw events, the last registration by default.
+	At time.Time
+}
+
+// Draw assigns start times from config.Start in config.StartDelta steps to
+// every competitor registered by event 1 and returns them as event 2 events.
+func Draw(events []model.CompetitorEvent, config model.Config, options DrawOptions, timeFormat string) ([]model.CompetitorEvent, error) {
+	var competitors []int
+	drawTime := options.At
+	for _, event := range events {
+		if event.ID != model.EventRegistered || slices.Contains(competitors, event.Competitor) {
+			continue
+		}
+		if options.At.IsZero() && (len(competitors) == 0 || event.Time.After(drawTime)) {
+			drawTime = event.Time
+		}
+		competitors = append(competitors, event.Competitor)
+	}
+	if len(competitors) == 0 {
+		return nil, errors.New("no registered competitors to draw")
+	}
+
+	// Every strategy starts from bib order, so the result does not depend on
+	// the order of registration.
+	slices.SortFunc(competitors, func(a, b int) int {
+		return compareBibs(config.Roster, a, b)
+	})
+
+	random := rand.New(rand.NewSource(options.Seed))
+	switch options.Strategy {
+	case DrawBibOrder:
+	case DrawRandom, "":
+		random.Shuffle(len(competitors), func(i, j int) {
+			competitors[i], competitors[j] = competitors[j], competitors[i]
+		})
+	case DrawSeededGroups:
+		if options.GroupSize <= 0 {
+			return nil, fmt.Errorf("group size must be positive, got %d", options.GroupSize)
+		}
+		for groupStart := 0; groupStart < len(competitors); groupStart += options.GroupSize {
+			group := competitors[groupStart:min(groupStart+options.GroupSize, len(competitors))]
+			random.Shuffle(len(group), func(i, j int) {
+				group[i], group[j] = group[j], group[i]
+			})
+		}
+	default:
+		return nil, fmt.Errorf("unknown draw strategy %q", options.Strategy)
+	}
+
+	startList := make([]model.CompetitorEvent, 0, len(competitors))
+	for i, competitorID := range competitors {
+		startList = append(startList, model.CompetitorEvent{
+			Time:        drawTime,
+			ID:          model.EventStartTimeSet,
+			Competitor:  competitorID,
+			ExtraParams: config.Start.Add(time.Duration(i) * config.StartDelta).Format(timeFormat),
+		})
+	}
+
+	return startList, nil
+}
+
+// compareBibs orders competitors by their bib on the roster, competitors
+// without a bib come last ordered by competitor ID.
+func compareBibs(roster model.Roster, a, b int) int {
+	bibA, bibB := roster[a].Bib, roster[b].Bib
+	hasBibA, hasBibB := bibA > 0, bibB > 0
+	if hasBibA != hasBibB {
+		if hasBibA {
+			return -1
+		}
+		return 1
+	}
+	if bibA != bibB {
+		return cmp.Compare(bibA, bibB)
+	}
+	return cmp.Compare(a, b)
+}
@@ -0,0 +1,27 @@
+package controller
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Maksim646/sunny_5_skiers/model"
+)
+
+// WriteEvents writes events in the input format, so that ParseEvents reads them back.
+func WriteEvents(w io.Writer, events []model.CompetitorEvent, timeFormat string) error {
+	for _, event := range events {
+		if _, err := io.WriteString(w, FormatEventLine(event, timeFormat)+"\n"); err != nil {
+			return fmt.Errorf("could not write event: %w", err)
+		}
+	}
+	return nil
+}
+
+// FormatEventLine formats an event as "[time] eventID competitorID extraParams".
+func FormatEventLine(event model.CompetitorEvent, timeFormat string) string {
+	line := fmt.Sprintf("[%s] %d %d", event.Time.Format(timeFormat), event.ID, event.Competitor)
+	if event.ExtraParams != "" {
+		line += " " + event.ExtraParams
+	}
+	return line
+}
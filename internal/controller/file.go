@@ -0,0 +1,28 @@
+package controller
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+)
+
+// WriteFile replaces the contents of the file at path with what write
+// writes through a buffer.
+func WriteFile(path string, write func(w io.Writer) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(file)
+	if err := write(writer); err != nil {
+		file.Close()
+		return err
+	}
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return fmt.Errorf("could not flush buffer: %w", err)
+	}
+	return file.Close()
+}
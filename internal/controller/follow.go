@@ -0,0 +1,283 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Maksim646/sunny_5_skiers/internal/output"
+	"github.com/Maksim646/sunny_5_skiers/internal/race"
+	"github.com/Maksim646/sunny_5_skiers/model"
+	"go.uber.org/zap"
+)
+
+// Tail reads the lines appended to a file, like tail -f.
+type Tail struct {
+	path    string
+	offset  int64
+	partial []byte
+	// info identifies the file read so far and seen holds its last bytes, to
+	// notice a file that was replaced or rewritten rather than appended to.
+	info os.FileInfo
+	seen []byte
+}
+
+// tailCheckSize is the number of bytes before the offset compared on every poll.
+const tailCheckSize = 64
+
+func NewTail(path string) *Tail {
+	return &Tail{path: path}
+}
+
+// Poll returns the complete lines appended since the previous call. A line
+// without its trailing newline is kept until the rest of it arrives. When the
+// file got shorter, was replaced by another file or its content up to the
+// offset changed, it is read again from the start and truncated is true.
+// A missing file is treated as an empty one.
+func (t *Tail) Poll() (lines []string, truncated bool, err error) {
+	file, err := os.Open(t.path)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, false, err
+	}
+	rewritten, err := t.rewritten(file, info)
+	if err != nil {
+		return nil, false, err
+	}
+	if rewritten {
+		t.offset = 0
+		t.partial = nil
+		t.seen = nil
+		truncated = true
+	}
+	t.info = info
+
+	if _, err := file.Seek(t.offset, io.SeekStart); err != nil {
+		return nil, truncated, err
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, truncated, err
+	}
+	t.offset += int64(len(data))
+	t.seen = append(t.seen, data...)
+	t.seen = t.seen[max(0, len(t.seen)-tailCheckSize):]
+
+	data = append(t.partial, data...)
+	end := bytes.LastIndexByte(data, '\n')
+	if end < 0 {
+		t.partial = data
+		return nil, truncated, nil
+	}
+	t.partial = append([]byte(nil), data[end+1:]...)
+
+	for _, line := range strings.Split(string(data[:end]), "\n") {
+		lines = append(lines, strings.TrimSuffix(line, "\r"))
+	}
+	return lines, truncated, nil
+}
+
+// rewritten reports whether file is no longer the one read up to the offset.
+func (t *Tail) rewritten(file *os.File, info os.FileInfo) (bool, error) {
+	if t.info == nil || t.offset == 0 {
+		return false, nil
+	}
+	if !os.SameFile(t.info, info) || info.Size() < t.offset {
+		return true, nil
+	}
+
+	last := make([]byte, len(t.seen))
+	if _, err := file.ReadAt(last, t.offset-int64(len(last))); err != nil {
+		return false, err
+	}
+	return !bytes.Equal(last, t.seen), nil
+}
+
+type FollowOptions struct {
+	EventsPath            string
+	OutputFilePath        string
+	ResultTablePath       string
+	TimeFormat            string
+	ReportTableTimeFormat string
+	// OutputFormat is the format of the result table, the output log is
+	// always appended as text.
+	OutputFormat      string
+	TargetsInFireLine int
+	Strict            bool
+}
+
+// Follower keeps the output log and the result table up to date while events
+// are appended to the events file.
+type Follower struct {
+	config  model.Config
+	options FollowOptions
+
+	tail       *Tail
+	engine     *race.Engine
+	validator  *SequenceValidator
+	timeline   *Timeline
+	lineNumber int
+}
+
+// NewFollower starts a new race and clears the output log and the result table.
+func NewFollower(config model.Config, options FollowOptions) (*Follower, error) {
+	if options.OutputFormat == "" {
+		options.OutputFormat = output.FormatText
+	}
+
+	f := &Follower{
+		config:  config,
+		options: options,
+		tail:    NewTail(options.EventsPath),
+	}
+	if err := f.reset(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Follow polls the events file every interval until ctx is done and then
+// finishes the race.
+func Follow(ctx context.Context, f *Follower, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := f.Step(); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return f.Finish()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Step processes the lines appended since the previous step.
+func (f *Follower) Step() error {
+	lines, truncated, err := f.tail.Poll()
+	if err != nil {
+		return err
+	}
+	if truncated {
+		zap.L().Warn("events file truncated, restarting the race", zap.String("path", f.options.EventsPath))
+		if err := f.reset(); err != nil {
+			return err
+		}
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+
+	var outputEvents []model.CompetitorEvent
+	applyErr := func() error {
+		for _, line := range lines {
+			f.lineNumber++
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
+
+			event, parseErr := parseAndValidate(line, f.options.TimeFormat)
+			if parseErr != nil {
+				parseErr.File = f.options.EventsPath
+				parseErr.Line = f.lineNumber
+				if f.options.Strict {
+					return parseErr
+				}
+				zap.L().Warn("skip invalid event", zap.Error(parseErr))
+				continue
+			}
+			event = f.timeline.Place(event)
+
+			if err := f.validator.Check(event); err != nil {
+				return err
+			}
+
+			produced, err := f.engine.Apply(event)
+			if err != nil {
+				return err
+			}
+			outputEvents = append(outputEvents, produced...)
+		}
+		return nil
+	}()
+
+	if err := f.appendLog(outputEvents); err != nil {
+		return err
+	}
+	if err := f.writeResultTable(); err != nil {
+		return err
+	}
+	return applyErr
+}
+
+// Finish reads the last lines of the events file, ends the race and writes
+// the final output log and result table, competitors still on the course
+// are marked NotFinished.
+func (f *Follower) Finish() error {
+	if err := f.Step(); err != nil {
+		return err
+	}
+	if err := f.appendLog(f.engine.Finish()); err != nil {
+		return err
+	}
+	return f.writeResultTable()
+}
+
+func (f *Follower) reset() error {
+	f.engine = race.NewEngine(f.config, f.options.TimeFormat, f.options.TargetsInFireLine)
+	f.validator = NewSequenceValidator(f.config, f.options.TimeFormat, f.options.TargetsInFireLine, f.options.Strict)
+	f.timeline = NewTimeline(f.config.Date)
+	f.lineNumber = 0
+
+	if err := os.WriteFile(f.options.OutputFilePath, nil, 0644); err != nil {
+		return err
+	}
+	return f.writeResultTable()
+}
+
+func (f *Follower) appendLog(outputEvents []model.CompetitorEvent) error {
+	if len(outputEvents) == 0 {
+		return nil
+	}
+
+	outputLogFile, err := os.OpenFile(f.options.OutputFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer outputLogFile.Close()
+
+	var sb strings.Builder
+	for _, event := range outputEvents {
+		sb.WriteString(f.engine.LogLine(event) + "\n")
+	}
+	if _, err := outputLogFile.WriteString(sb.String()); err != nil {
+		return fmt.Errorf("could not write to file: %w", err)
+	}
+	return nil
+}
+
+// writeResultTable replaces the result table at once, so a reader never
+// sees a half written table.
+func (f *Follower) writeResultTable() error {
+	tmpPath := f.options.ResultTablePath + ".tmp"
+	if err := GenerateResultingTable(f.engine, tmpPath, f.options.OutputFormat, f.options.ReportTableTimeFormat); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, f.options.ResultTablePath)
+}
@@ -1,173 +1,133 @@
 package controller
 
 import (
-	"bufio"
 	"fmt"
-	"os"
-	"sort"
+	"io"
+	"slices"
 	"strings"
-	"time"
 
+	"github.com/Maksim646/sunny_5_skiers/internal/durationfmt"
+	"github.com/Maksim646/sunny_5_skiers/internal/output"
+	"github.com/Maksim646/sunny_5_skiers/internal/race"
 	"github.com/Maksim646/sunny_5_skiers/model"
-	"go.uber.org/zap"
 )
 
-func GenerateResultingTable(events []model.CompetitorEvent, resultTablePath string, timeFormat string, config model.Config, targetsInFireLine int) error {
-	resultTableFile, err := os.OpenFile(resultTablePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
-	if err != nil {
-		return err
+// GenerateResultingTable writes the result table to the file at
+// resultTablePath, see WriteResultingTable.
+func GenerateResultingTable(engine *race.Engine, resultTablePath string, outputFormat string, reportTableTimeFormat string) error {
+	if !slices.Contains(output.Formats, outputFormat) {
+		return fmt.Errorf("unknown output format %q", outputFormat)
 	}
-	defer resultTableFile.Close()
 
-	resultTableFileWriter := bufio.NewWriter(resultTableFile)
+	return WriteFile(resultTablePath, func(w io.Writer) error {
+		return WriteResultingTable(w, engine, outputFormat, reportTableTimeFormat)
+	})
+}
 
-	competitorEvents := make(map[int][]model.CompetitorEvent)
-	for _, event := range events {
-		if event.Competitor == 0 {
-			zap.L().Info(fmt.Sprintf("warning: event without competitor ID: %+v", event))
-		}
-		competitorEvents[event.Competitor] = append(competitorEvents[event.Competitor], event)
+// WriteResultingTable writes the result table to w in outputFormat, one of
+// output.Formats. Times are written with the durationfmt layout reportTableTimeFormat.
+func WriteResultingTable(w io.Writer, engine *race.Engine, outputFormat string, reportTableTimeFormat string) error {
+	if !slices.Contains(output.Formats, outputFormat) {
+		return fmt.Errorf("unknown output format %q", outputFormat)
 	}
-
-	var sortedReports []model.CompetitorReport
-	for competitorID, events := range competitorEvents {
-		sort.Slice(events, func(i, j int) bool { return events[i].Time.Before(events[j].Time) })
-		report := processCompetitorEvents(competitorID, events, config, timeFormat, targetsInFireLine)
-		sortedReports = append(sortedReports, report)
+	timeLayout, err := durationfmt.Compile(reportTableTimeFormat)
+	if err != nil {
+		return err
 	}
 
-	sort.Slice(sortedReports, func(i, j int) bool {
-		a, b := sortedReports[i], sortedReports[j]
-
-		if (a.Status == model.CompetitorNotFinished || a.Status == model.CompetitorNotStarted) &&
-			(b.Status != model.CompetitorNotFinished && b.Status != model.CompetitorNotStarted) {
-			return false
-		}
-		if (b.Status == model.CompetitorNotFinished || b.Status == model.CompetitorNotStarted) &&
-			(a.Status != model.CompetitorNotFinished && a.Status != model.CompetitorNotStarted) {
-			return true
-		}
-
-		return a.TotalTime < b.TotalTime
-	})
-
-	for _, report := range sortedReports {
-		reportLine := formatCompetitorReport(report, timeFormat, config)
-		_, err := resultTableFileWriter.WriteString(reportLine + "\n")
-		if err != nil {
-			return fmt.Errorf("could not write report to file: %w", err)
-		}
+	reports := output.AtDisplayResolution(engine.Reports(), engine.Config())
+
+	switch outputFormat {
+	case output.FormatJSON:
+		err = output.WriteResultsJSON(w, reports)
+	case output.FormatCSV:
+		err = output.WriteResultsCSV(w, reports, engine.Config())
+	case output.FormatHTML:
+		err = output.WriteResultsHTML(w, reports, engine.Config(), timeLayout)
+	default:
+		err = writeResultTableText(w, reports, engine.Config(), timeLayout)
 	}
-
-	if err := resultTableFileWriter.Flush(); err != nil {
-		return fmt.Errorf("could not flush report buffer: %w", err)
+	if err != nil {
+		return fmt.Errorf("could not write report: %w", err)
 	}
 
 	return nil
 }
 
-func processCompetitorEvents(competitorID int, events []model.CompetitorEvent, config model.Config, timeFormat string, targetsInFireLine int) model.CompetitorReport {
-
-	var (
-		lapsInfo            []model.LapInfo
-		penaltyLapsInfo     []model.LapInfo
-		startTime           time.Time
-		finishTime          time.Time
-		lapStartTime        time.Time
-		penaltyLapStartTime time.Time
-		laps                int
-		hits                int
-		speed               float64
-	)
-	status := model.CompetitorNotStarted
-
-	for _, e := range events {
-		switch e.ID {
-		case model.EventStart:
-			startTime = e.Time
-			status = model.CompetitorStarted
-			lapStartTime = e.Time
-		case model.EventTargetHit:
-			hits += 1
-		case model.EventPenaltyLapStart:
-			penaltyLapStartTime = e.Time
-		case model.EventPenaltyLapEnd:
-			currentPenaltyLapEnd := e.Time
-			penaltyLapDuration := currentPenaltyLapEnd.Sub(penaltyLapStartTime)
-
-			penaltyLapInfo := model.LapInfo{
-				Time:  penaltyLapDuration,
-				Speed: float64(config.PenaltyLen) / penaltyLapDuration.Seconds(),
-			}
-
-			penaltyLapsInfo = append(penaltyLapsInfo, penaltyLapInfo)
-			penaltyLapStartTime = currentPenaltyLapEnd
-		case model.EventLapCompleted:
-			laps += 1
-			if laps == config.Laps {
-				finishTime = e.Time
-			}
-
-			currentLapEnd := e.Time
-			lapDuration := currentLapEnd.Sub(lapStartTime)
+// WriteResultTableText writes reports as the text result table with the
+// durationfmt layout reportTableTimeFormat, ReadResultTable reads it back.
+func WriteResultTableText(w io.Writer, reports []model.CompetitorReport, config model.Config, reportTableTimeFormat string) error {
+	timeLayout, err := durationfmt.Compile(reportTableTimeFormat)
+	if err != nil {
+		return err
+	}
+	return writeResultTableText(w, reports, config, timeLayout)
+}
 
-			seconds := lapDuration.Seconds()
-			if seconds > 0 {
-				speed = float64(config.LapLen) / seconds
+// writeResultTableText writes the overall ranking followed, if any competitor
+// has a category, by a "# category" section per category.
+func writeResultTableText(w io.Writer, reports []model.CompetitorReport, config model.Config, timeLayout durationfmt.Layout) error {
+	writeSection := func(title string, reports []model.CompetitorReport) error {
+		if title != "" {
+			if _, err := io.WriteString(w, "# "+title+"\n"); err != nil {
+				return err
 			}
-
-			lapInfo := model.LapInfo{
-				Time:  lapDuration,
-				Speed: speed,
+		}
+		for _, report := range reports {
+			if _, err := io.WriteString(w, formatCompetitorReport(report, timeLayout, config)+"\n"); err != nil {
+				return err
 			}
-			lapsInfo = append(lapsInfo, lapInfo)
-			lapStartTime = currentLapEnd
-
-		case model.EventNotFinished:
-			status = model.CompetitorNotFinished
 		}
+		return nil
 	}
 
-	if status == model.CompetitorStarted && finishTime.IsZero() {
-		status = model.CompetitorNotFinished
+	categories := output.Categories(reports)
+	if len(categories) == 0 {
+		return writeSection("", reports)
 	}
 
-	totalTime := time.Duration(0)
-	if !startTime.IsZero() && !finishTime.IsZero() {
-		totalTime = finishTime.Sub(startTime)
+	if err := writeSection(output.OverallTitle, reports); err != nil {
+		return err
 	}
-
-	return model.CompetitorReport{
-		CompetitorID: competitorID,
-		TotalTime:    totalTime,
-		Status:       status,
-		Laps:         lapsInfo,
-		PenaltyLaps:  penaltyLapsInfo,
-		Hits:         hits,
-		Shots:        targetsInFireLine * config.FiringLines,
+	for _, category := range categories {
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return err
+		}
+		if err := writeSection(category, output.InCategory(reports, category)); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
-func formatCompetitorReport(report model.CompetitorReport, reportTableTimeFormat string, config model.Config) string {
+func formatCompetitorReport(report model.CompetitorReport, timeLayout durationfmt.Layout, config model.Config) string {
 	var sb strings.Builder
 
-	if report.Status == "NotStarted" || report.Status == "NotFinished" {
+	if report.Status != model.CompetitorStarted {
 		sb.WriteString(fmt.Sprintf("[%s] %d ", report.Status, report.CompetitorID))
 	} else {
-		sb.WriteString(fmt.Sprintf("[%s] %d ", formatDuration(report.TotalTime, reportTableTimeFormat), report.CompetitorID))
+		sb.WriteString(fmt.Sprintf("[%s] %d ", timeLayout.Format(report.TotalTime), report.CompetitorID))
+	}
+	if report.Athlete.Name != "" {
+		sb.WriteString(fmt.Sprintf("%q ", report.Athlete.Name))
+	}
+	if report.Place > 1 {
+		sb.WriteString(fmt.Sprintf("+%s ", timeLayout.Format(report.GapToLeader)))
 	}
 
-	sb.WriteString(formatLapList(report.Laps, config.Laps, reportTableTimeFormat))
+	sb.WriteString(formatLapList(report.Laps, config.Laps, timeLayout))
 	sb.WriteString(" ")
-	sb.WriteString(formatLapList(report.PenaltyLaps, config.FiringLines, reportTableTimeFormat))
+	sb.WriteString(formatPenalty(report, timeLayout))
 	sb.WriteString(" ")
 
 	sb.WriteString(fmt.Sprintf("%d/%d", report.Hits, report.Shots))
+	sb.WriteString(" ")
+	sb.WriteString(formatFiringRangeList(report.FiringRanges, config.FiringLines, timeLayout))
 
 	return sb.String()
 }
 
-func formatLapList(laps []model.LapInfo, expectedCount int, timeFmt string) string {
+func formatLapList(laps []model.LapInfo, expectedCount int, timeLayout durationfmt.Layout) string {
 	var sb strings.Builder
 	sb.WriteString("[")
 
@@ -176,7 +136,41 @@ func formatLapList(laps []model.LapInfo, expectedCount int, timeFmt string) stri
 			sb.WriteString(", ")
 		}
 		if i < len(laps) {
-			sb.WriteString(fmt.Sprintf("{%s, %.3f}", formatDuration(laps[i].Time, timeFmt), laps[i].Speed))
+			sb.WriteString(fmt.Sprintf("{%s, %.3f}", timeLayout.Format(laps[i].Time), laps[i].Speed))
+		} else {
+			sb.WriteString("{,}")
+		}
+	}
+
+	sb.WriteString("]")
+	return sb.String()
+}
+
+// formatPenalty writes the total time and the average speed over all penalty
+// loops, followed by missed:N when required loops were not run.
+func formatPenalty(report model.CompetitorReport, timeLayout durationfmt.Layout) string {
+	penalty := "{,}"
+	if report.PenaltyLoopsRun > 0 || report.PenaltyTime > 0 {
+		penalty = fmt.Sprintf("{%s, %.3f}", timeLayout.Format(report.PenaltyTime), report.PenaltySpeed)
+	}
+	if report.MissedPenaltyLoops > 0 {
+		penalty += fmt.Sprintf(" missed:%d", report.MissedPenaltyLoops)
+	}
+	return penalty
+}
+
+// formatFiringRangeList writes every firing range visit as
+// {firingRange, hitMask, time}, where the mask has 1 for a hit and 0 for a miss.
+func formatFiringRangeList(visits []model.FiringRangeVisit, expectedCount int, timeLayout durationfmt.Layout) string {
+	var sb strings.Builder
+	sb.WriteString("[")
+
+	for i := 0; i < max(expectedCount, len(visits)); i++ {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		if i < len(visits) {
+			sb.WriteString(fmt.Sprintf("{%d, %s, %s}", visits[i].FiringRange, output.HitMask(visits[i]), timeLayout.Format(visits[i].Time)))
 		} else {
 			sb.WriteString("{,}")
 		}
@@ -0,0 +1,25 @@
+package controller
+
+import "fmt"
+
+// ParseError describes an input line that could not be parsed or did not
+// pass validation.
+type ParseError struct {
+	File   string
+	Line   int
+	Text   string
+	Reason string
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	msg := fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Reason)
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return fmt.Sprintf("%s (line %q)", msg, e.Text)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,341 @@
+package controller
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Maksim646/sunny_5_skiers/internal/durationfmt"
+	"github.com/Maksim646/sunny_5_skiers/internal/output"
+	"github.com/Maksim646/sunny_5_skiers/model"
+)
+
+// DefaultReportTableTimeFormat is the durationfmt layout of the times in the
+// text result table.
+const DefaultReportTableTimeFormat = "hh:mm:ss.fff"
+
+// resultMarkers are the statuses written in brackets instead of a total time.
+var resultMarkers = []model.Status{
+	model.CompetitorRunning,
+	model.CompetitorLapped,
+	model.CompetitorNotFinished,
+	model.CompetitorNotStarted,
+	model.CompetitorDisqualified,
+}
+
+// ParseResultTable reads a text result table written with the default time format.
+func ParseResultTable(r io.Reader) ([]model.CompetitorReport, error) {
+	return ReadResultTable(r, "result table", DefaultReportTableTimeFormat)
+}
+
+// ReadResultTable reads a text result table written with the durationfmt
+// layout reportTableTimeFormat. name is reported in parse errors.
+//
+// The reports are in the order of the overall section, the category sections
+// only set Category and CategoryPlace. Places are counted in table order,
+// competitors with equal total times as written share a place, so they
+// match the places of the race only when the layout shows times at the
+// ranking resolution. The table does not keep
+// the number of penalty loops run, the reason of a status or the time penalty
+// of the individual format, which is already part of the total time, so
+// PenaltyLoopsRun, Reason and TimePenalty are left zero.
+func ReadResultTable(r io.Reader, name string, reportTableTimeFormat string) ([]model.CompetitorReport, error) {
+	timeLayout, err := durationfmt.Compile(reportTableTimeFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	var overall []model.CompetitorReport
+	index := make(map[int]int)
+	category := ""
+	var places placeCounter
+
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if title, ok := strings.CutPrefix(line, "#"); ok {
+			category = strings.TrimSpace(title)
+			if category == output.OverallTitle {
+				category = ""
+			}
+			places = placeCounter{}
+			continue
+		}
+
+		report, reason, err := parseResultLine(line, timeLayout)
+		if reason != "" {
+			return nil, &ParseError{File: name, Line: lineNumber, Text: line, Reason: reason, Err: err}
+		}
+		place := places.next(report)
+
+		if category == "" {
+			if _, ok := index[report.CompetitorID]; ok {
+				return nil, &ParseError{File: name, Line: lineNumber, Text: line, Reason: fmt.Sprintf("duplicate competitor ID %d", report.CompetitorID)}
+			}
+			report.Place = place
+			index[report.CompetitorID] = len(overall)
+			overall = append(overall, report)
+			continue
+		}
+
+		i, ok := index[report.CompetitorID]
+		if !ok {
+			return nil, &ParseError{File: name, Line: lineNumber, Text: line, Reason: fmt.Sprintf("competitor(%d) is not in the overall section", report.CompetitorID)}
+		}
+		overall[i].Category = category
+		overall[i].CategoryPlace = place
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return overall, nil
+}
+
+// placeCounter numbers the competitors with a result in table order.
+type placeCounter struct {
+	count    int
+	place    int
+	lastTime time.Duration
+}
+
+func (c *placeCounter) next(report model.CompetitorReport) int {
+	if report.Status != model.CompetitorStarted {
+		return 0
+	}
+	c.count++
+	if c.count == 1 || report.TotalTime != c.lastTime {
+		c.place = c.count
+	}
+	c.lastTime = report.TotalTime
+	return c.place
+}
+
+// parseResultLine reads a line written by formatCompetitorReport. A
+// non-empty reason reports an invalid line.
+func parseResultLine(line string, timeLayout durationfmt.Layout) (model.CompetitorReport, string, error) {
+	var report model.CompetitorReport
+	s := &resultScanner{rest: line}
+
+	marker, ok := s.enclosed('[', ']')
+	if !ok {
+		return report, "expected [result]", nil
+	}
+	if status := model.Status(marker); slices.Contains(resultMarkers, status) {
+		report.Status = status
+	} else {
+		totalTime, err := timeLayout.Parse(marker)
+		if err != nil {
+			return report, fmt.Sprintf("invalid result %q", marker), err
+		}
+		report.Status = model.CompetitorStarted
+		report.TotalTime = totalTime
+	}
+
+	id, err := strconv.Atoi(s.token())
+	if err != nil {
+		return report, "invalid competitor ID", err
+	}
+	report.CompetitorID = id
+
+	if s.peek('"') {
+		quoted, err := strconv.QuotedPrefix(s.rest)
+		if err != nil {
+			return report, "invalid name", err
+		}
+		report.Athlete.Name, _ = strconv.Unquote(quoted)
+		s.rest = strings.TrimLeft(s.rest[len(quoted):], " ")
+	}
+
+	if s.peek('+') {
+		gap := s.token()
+		report.GapToLeader, err = timeLayout.Parse(gap[1:])
+		if err != nil {
+			return report, fmt.Sprintf("invalid gap %q", gap), err
+		}
+	}
+
+	laps, ok := s.groups()
+	if !ok {
+		return report, "expected [laps]", nil
+	}
+	for _, lap := range laps {
+		if lap == nil {
+			continue
+		}
+		info, reason, err := parseTimeAndSpeed(lap, timeLayout)
+		if reason != "" {
+			return report, "lap " + reason, err
+		}
+		report.Laps = append(report.Laps, info)
+	}
+
+	penalty, ok := s.group()
+	if !ok {
+		return report, "expected {penalty}", nil
+	}
+	if strings.HasPrefix(s.rest, "missed:") {
+		missed := s.token()
+		report.MissedPenaltyLoops, err = strconv.Atoi(strings.TrimPrefix(missed, "missed:"))
+		if err != nil {
+			return report, fmt.Sprintf("invalid missed penalty loops %q", missed), err
+		}
+	}
+
+	hits, shots, ok := strings.Cut(s.token(), "/")
+	if report.Hits, err = strconv.Atoi(hits); !ok || err != nil {
+		return report, "expected hits/shots", err
+	}
+	if report.Shots, err = strconv.Atoi(shots); err != nil {
+		return report, "expected hits/shots", err
+	}
+
+	if penalty != nil {
+		info, reason, err := parseTimeAndSpeed(penalty, timeLayout)
+		if reason != "" {
+			return report, "penalty " + reason, err
+		}
+		report.PenaltyTime = info.Time
+		report.PenaltySpeed = info.Speed
+	}
+
+	visits, ok := s.groups()
+	if !ok {
+		return report, "expected [firing ranges]", nil
+	}
+	for _, fields := range visits {
+		if fields == nil {
+			continue
+		}
+		visit, reason, err := parseFiringRangeVisit(fields, timeLayout)
+		if reason != "" {
+			return report, reason, err
+		}
+		report.FiringRanges = append(report.FiringRanges, visit)
+	}
+
+	if s.rest != "" {
+		return report, fmt.Sprintf("unexpected %q", s.rest), nil
+	}
+	return report, "", nil
+}
+
+func parseTimeAndSpeed(fields []string, timeLayout durationfmt.Layout) (model.LapInfo, string, error) {
+	var info model.LapInfo
+	if len(fields) != 2 {
+		return info, "expects {time, speed}", nil
+	}
+
+	var err error
+	if info.Time, err = timeLayout.Parse(fields[0]); err != nil {
+		return info, fmt.Sprintf("has invalid time %q", fields[0]), err
+	}
+	if info.Speed, err = strconv.ParseFloat(fields[1], 64); err != nil {
+		return info, fmt.Sprintf("has invalid speed %q", fields[1]), err
+	}
+	return info, "", nil
+}
+
+// parseFiringRangeVisit reads {firingRange, hitMask, time}.
+func parseFiringRangeVisit(fields []string, timeLayout durationfmt.Layout) (model.FiringRangeVisit, string, error) {
+	var visit model.FiringRangeVisit
+	if len(fields) != 3 {
+		return visit, "firing range expects {firingRange, hitMask, time}", nil
+	}
+
+	var err error
+	if visit.FiringRange, err = strconv.Atoi(fields[0]); err != nil {
+		return visit, fmt.Sprintf("invalid firing range number %q", fields[0]), err
+	}
+	visit.Targets = len(fields[1])
+	for i, c := range fields[1] {
+		switch c {
+		case '1':
+			visit.HitTargets = append(visit.HitTargets, i+1)
+		case '0':
+			visit.Misses++
+		default:
+			return visit, fmt.Sprintf("invalid hit mask %q", fields[1]), nil
+		}
+	}
+	if visit.Time, err = timeLayout.Parse(fields[2]); err != nil {
+		return visit, fmt.Sprintf("firing range has invalid time %q", fields[2]), err
+	}
+	return visit, "", nil
+}
+
+// resultScanner splits a result line into its space separated parts.
+type resultScanner struct {
+	rest string
+}
+
+func (s *resultScanner) peek(c byte) bool {
+	return len(s.rest) > 0 && s.rest[0] == c
+}
+
+// token returns the text up to the next space.
+func (s *resultScanner) token() string {
+	token, rest, _ := strings.Cut(s.rest, " ")
+	s.rest = strings.TrimLeft(rest, " ")
+	return token
+}
+
+// enclosed returns the text between open and the first close.
+func (s *resultScanner) enclosed(open byte, close byte) (string, bool) {
+	if !s.peek(open) {
+		return "", false
+	}
+	end := strings.IndexByte(s.rest, close)
+	if end < 0 {
+		return "", false
+	}
+	text := s.rest[1:end]
+	s.rest = strings.TrimLeft(s.rest[end+1:], " ")
+	return text, true
+}
+
+// group reads {a, b, ...}, a {,} placeholder is returned as nil fields.
+func (s *resultScanner) group() ([]string, bool) {
+	text, ok := s.enclosed('{', '}')
+	if !ok {
+		return nil, false
+	}
+	if strings.TrimSpace(text) == "," {
+		return nil, true
+	}
+	fields := strings.Split(text, ",")
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+	return fields, true
+}
+
+// groups reads [{...}, {...}], the list may be empty.
+func (s *resultScanner) groups() ([][]string, bool) {
+	text, ok := s.enclosed('[', ']')
+	if !ok {
+		return nil, false
+	}
+
+	var groups [][]string
+	inner := &resultScanner{rest: strings.TrimSpace(text)}
+	for inner.rest != "" {
+		fields, ok := inner.group()
+		if !ok {
+			return nil, false
+		}
+		groups = append(groups, fields)
+		inner.rest = strings.TrimLeft(strings.TrimPrefix(inner.rest, ","), " ")
+	}
+	return groups, true
+}
@@ -3,11 +3,18 @@ package controller
 import (
 	"bufio"
 	"encoding/json"
+	"fmt"
+	"io"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
+	// The race time zone is loaded from the embedded database when the
+	// system has none.
+	_ "time/tzdata"
 
+	"github.com/Maksim646/sunny_5_skiers/internal/output"
 	"github.com/Maksim646/sunny_5_skiers/model"
 )
 
@@ -18,68 +25,224 @@ func ParseEvents(eventPath string, eventTimeFormat string) ([]model.CompetitorEv
 	}
 	defer file.Close()
 
-	var events []model.CompetitorEvent
-	scanner := bufio.NewScanner(file)
+	return ReadEvents(file, eventPath, eventTimeFormat)
+}
 
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" {
-			continue
-		}
+// ReadEvents reads every event of the stream. name is reported in parse errors.
+func ReadEvents(r io.Reader, name string, eventTimeFormat string) ([]model.CompetitorEvent, error) {
+	var events []model.CompetitorEvent
 
-		parts := strings.Fields(line)
-		if len(parts) < 3 {
-			return nil, err
+	reader := NewEventReader(r, name, eventTimeFormat)
+	for {
+		event, err := reader.Next()
+		if err == io.EOF {
+			return events, nil
 		}
-
-		timeStr := strings.Trim(parts[0], "[]")
-		eventTime, err := time.Parse(eventTimeFormat, timeStr)
 		if err != nil {
 			return nil, err
 		}
+		events = append(events, event)
+	}
+}
 
-		eventID, err := strconv.Atoi(parts[1])
-		if err != nil {
-			return nil, err
+// EventReader parses events from a stream one line at a time.
+type EventReader struct {
+	scanner    *bufio.Scanner
+	name       string
+	timeFormat string
+	lineNumber int
+}
+
+func NewEventReader(r io.Reader, name string, eventTimeFormat string) *EventReader {
+	return &EventReader{
+		scanner:    bufio.NewScanner(r),
+		name:       name,
+		timeFormat: eventTimeFormat,
+	}
+}
+
+// Next returns the next event of the stream, or io.EOF at its end.
+func (r *EventReader) Next() (model.CompetitorEvent, error) {
+	for r.scanner.Scan() {
+		r.lineNumber++
+		line := strings.TrimSpace(r.scanner.Text())
+		if line == "" {
+			continue
 		}
 
-		competitorID, err := strconv.Atoi(parts[2])
+		event, err := parseAndValidate(line, r.timeFormat)
 		if err != nil {
-			return nil, err
+			err.File = r.name
+			err.Line = r.lineNumber
+			return model.CompetitorEvent{}, err
 		}
+		return event, nil
+	}
 
-		var extra string
-		if len(parts) > 3 {
-			extra = strings.Join(parts[3:], " ")
-		}
+	if err := r.scanner.Err(); err != nil {
+		return model.CompetitorEvent{}, err
+	}
+	return model.CompetitorEvent{}, io.EOF
+}
+
+// ParseEventLine parses and validates a single event line.
+func ParseEventLine(line string, eventTimeFormat string) (model.CompetitorEvent, error) {
+	event, err := parseAndValidate(strings.TrimSpace(line), eventTimeFormat)
+	if err != nil {
+		return model.CompetitorEvent{}, err
+	}
+	return event, nil
+}
+
+// parseAndValidate returns an error without file and line set.
+func parseAndValidate(line string, eventTimeFormat string) (model.CompetitorEvent, *ParseError) {
+	event, parseErr := parseEventLine(line, eventTimeFormat)
+	if parseErr != nil {
+		return model.CompetitorEvent{}, parseErr
+	}
+	if err := ValidateEvent(event, eventTimeFormat); err != nil {
+		return model.CompetitorEvent{}, &ParseError{Text: line, Reason: err.Error()}
+	}
+	return event, nil
+}
+
+// parseEventLine splits a "[time] eventID competitorID extraParams" line.
+// The returned error has no file and line set.
+func parseEventLine(line string, eventTimeFormat string) (model.CompetitorEvent, *ParseError) {
+	parts := strings.Fields(line)
+	if len(parts) < 3 {
+		return model.CompetitorEvent{}, &ParseError{Text: line, Reason: "expected [time] eventID competitorID"}
+	}
+
+	if !strings.HasPrefix(parts[0], "[") || !strings.HasSuffix(parts[0], "]") {
+		return model.CompetitorEvent{}, &ParseError{Text: line, Reason: "time must be enclosed in brackets"}
+	}
+
+	timeStr := strings.Trim(parts[0], "[]")
+	eventTime, err := ParseEventTime(timeStr, eventTimeFormat)
+	if err != nil {
+		return model.CompetitorEvent{}, &ParseError{Text: line, Reason: "invalid time", Err: err}
+	}
+
+	eventID, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return model.CompetitorEvent{}, &ParseError{Text: line, Reason: "invalid event ID", Err: err}
+	}
 
-		event := model.CompetitorEvent{
-			Time:        eventTime,
-			ID:          eventID,
-			Competitor:  competitorID,
-			ExtraParams: extra,
+	competitorID, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return model.CompetitorEvent{}, &ParseError{Text: line, Reason: "invalid competitor ID", Err: err}
+	}
+
+	var extra string
+	if len(parts) > 3 {
+		extra = strings.Join(parts[3:], " ")
+	}
+
+	return model.CompetitorEvent{
+		Time:        eventTime,
+		ID:          eventID,
+		Competitor:  competitorID,
+		ExtraParams: extra,
+	}, nil
+}
+
+// extendedDateLayout prefixes the event time format in timestamps with a
+// full date, e.g. 2025-05-06T09:05:59.867, 2025-05-06T09:05:59.867+02:00 or
+// 2025-05-06T09:05:59.867Z.
+const extendedDateLayout = "2006-01-02T"
+
+// ParseEventTime reads an event time, either a clock time in eventTimeFormat
+// or an extended timestamp with a full date and an optional UTC offset. The
+// seconds may have more fractional digits than the format, e.g.
+// 09:05:59.867250, never fewer. A timestamp without an offset is returned in
+// UTC and placed in the race time zone by a Timeline.
+func ParseEventTime(raw string, eventTimeFormat string) (time.Time, error) {
+	eventTime, err := parseEventTimeExact(raw, eventTimeFormat)
+	if err == nil {
+		return eventTime, nil
+	}
+
+	if trimmed, extra, ok := cutExtraFraction(raw, fractionWidth(eventTimeFormat)); ok {
+		if eventTime, trimmedErr := parseEventTimeExact(trimmed, eventTimeFormat); trimmedErr == nil {
+			return eventTime.Add(extra), nil
 		}
+	}
+	return time.Time{}, err
+}
 
-		events = append(events, event)
+// parseEventTimeExact reads a time with exactly the fraction digits of the format.
+func parseEventTimeExact(raw string, eventTimeFormat string) (time.Time, error) {
+	eventTime, err := time.Parse(eventTimeFormat, raw)
+	if err == nil || !strings.Contains(raw, "T") {
+		return eventTime, err
+	}
+	if extended, extErr := time.Parse(extendedDateLayout+eventTimeFormat+"Z07:00", raw); extErr == nil {
+		// A Timeline takes time.UTC for a time without an offset, so a given
+		// offset, Z included, is kept in a zone of its own.
+		_, offset := extended.Zone()
+		return extended.In(time.FixedZone("", offset)), nil
 	}
+	if extended, extErr := time.Parse(extendedDateLayout+eventTimeFormat, raw); extErr == nil {
+		return extended, nil
+	}
+	return time.Time{}, err
+}
 
-	if err := scanner.Err(); err != nil {
-		return nil, err
+// fractionWidth is the number of fraction digits of the format, e.g. 3 for
+// 15:04:05.000.
+func fractionWidth(eventTimeFormat string) int {
+	_, fraction, ok := strings.Cut(eventTimeFormat, ".")
+	if !ok {
+		return 0
 	}
+	return len(fraction) - len(strings.TrimLeft(fraction, "0"))
+}
 
-	return events, nil
+// cutExtraFraction removes the fraction digits beyond width from raw and
+// returns them as a duration. ok is false unless there are more than width
+// digits, digits beyond nanoseconds are dropped.
+func cutExtraFraction(raw string, width int) (trimmed string, extra time.Duration, ok bool) {
+	dot := strings.IndexByte(raw, '.')
+	if width == 0 || dot < 0 {
+		return raw, 0, false
+	}
+
+	digits := 0
+	for dot+1+digits < len(raw) && raw[dot+1+digits] >= '0' && raw[dot+1+digits] <= '9' {
+		digits++
+	}
+	if digits <= width {
+		return raw, 0, false
+	}
+
+	scale := time.Duration(1)
+	for i := width; i < 9; i++ {
+		scale *= 10
+	}
+	for _, c := range raw[dot+1+width : dot+1+min(digits, 9)] {
+		scale /= 10
+		extra += time.Duration(c-'0') * scale
+	}
+
+	return raw[:dot+1+width] + raw[dot+1+digits:], extra, true
 }
 
 func ParseConfig(path string, timeFormat string, timeDurationFormat string) (model.Config, error) {
-	var config model.Config
-
 	file, err := os.Open(path)
 	if err != nil {
-		return config, err
+		return model.Config{}, err
 	}
 	defer file.Close()
 
-	decoder := json.NewDecoder(file)
+	return ReadConfig(file, timeFormat, timeDurationFormat)
+}
+
+// ReadConfig reads the JSON config of the race and checks its values.
+func ReadConfig(r io.Reader, timeFormat string, timeDurationFormat string) (model.Config, error) {
+	var config model.Config
+
+	decoder := json.NewDecoder(r)
 	if err := decoder.Decode(&config); err != nil {
 		return config, err
 	}
@@ -90,16 +253,125 @@ func ParseConfig(path string, timeFormat string, timeDurationFormat string) (mod
 	}
 	config.Start = startTime
 
-	deltaParsed, err := time.Parse(timeDurationFormat, config.DeltaRaw)
+	config.StartDelta, err = ParseClockDuration(config.DeltaRaw, timeDurationFormat)
 	if err != nil {
 		return config, err
 	}
 
-	config.StartDelta = time.Duration(
-		deltaParsed.Hour()*int(time.Hour) +
-			deltaParsed.Minute()*int(time.Minute) +
-			deltaParsed.Second()*int(time.Second),
-	)
+	if config.PenaltyTimeRaw != "" {
+		config.PenaltyTime, err = ParseClockDuration(config.PenaltyTimeRaw, timeDurationFormat)
+		if err != nil {
+			return config, err
+		}
+	}
+
+	resolutions := []struct {
+		name   string
+		raw    string
+		parsed *time.Duration
+	}{
+		{"input", config.InputResolutionRaw, &config.InputResolution},
+		{"ranking", config.RankingResolutionRaw, &config.RankingResolution},
+		{"display", config.DisplayResolutionRaw, &config.DisplayResolution},
+	}
+	for _, resolution := range resolutions {
+		if resolution.raw == "" {
+			continue
+		}
+		*resolution.parsed, err = time.ParseDuration(resolution.raw)
+		if err != nil {
+			return config, err
+		}
+		if *resolution.parsed <= 0 {
+			return config, fmt.Errorf("%s resolution must be positive, got %s", resolution.name, resolution.raw)
+		}
+	}
+
+	if config.MaxPenaltySpeed < 0 {
+		return config, fmt.Errorf("max penalty speed must not be negative, got %g", config.MaxPenaltySpeed)
+	}
+
+	if config.Rounding == "" {
+		config.Rounding = model.RoundingTruncate
+	}
+	if !slices.Contains(model.Roundings, config.Rounding) {
+		return config, fmt.Errorf("unknown rounding %q", config.Rounding)
+	}
+
+	config.Date, err = raceDate(config.DateRaw, config.TimeZoneRaw)
+	if err != nil {
+		return config, err
+	}
+
+	if config.Format == "" {
+		config.Format = model.FormatSprint
+	}
+	if !slices.Contains(model.Formats, config.Format) {
+		return config, fmt.Errorf("unknown competition format %q", config.Format)
+	}
+
+	if err := validateCategories(config.Categories); err != nil {
+		return config, err
+	}
 
 	return config, nil
 }
+
+// raceDate returns the midnight of the race day in its time zone.
+func raceDate(dateRaw string, timeZoneRaw string) (time.Time, error) {
+	location := time.UTC
+	if timeZoneRaw != "" {
+		var err error
+		location, err = time.LoadLocation(timeZoneRaw)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("unknown time zone %q", timeZoneRaw)
+		}
+	}
+
+	if dateRaw == "" {
+		return time.Date(0, time.January, 1, 0, 0, 0, 0, location), nil
+	}
+	date, err := time.ParseInLocation(time.DateOnly, dateRaw, location)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid race date %q", dateRaw)
+	}
+	return date, nil
+}
+
+// validateCategories makes sure no competitor is in two categories and no
+// category takes the name of the overall ranking.
+func validateCategories(categories map[string][]int) error {
+	names := make([]string, 0, len(categories))
+	for name := range categories {
+		if name == output.OverallTitle {
+			return fmt.Errorf("category %q is reserved for the overall ranking", name)
+		}
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	categoryOf := make(map[int]string)
+	for _, name := range names {
+		for _, competitorID := range categories[name] {
+			if other, ok := categoryOf[competitorID]; ok {
+				return fmt.Errorf("competitor(%d) is in categories %q and %q", competitorID, other, name)
+			}
+			categoryOf[competitorID] = name
+		}
+	}
+	return nil
+}
+
+// ParseClockDuration reads a duration written as a wall clock time, e.g. 00:01:30.
+func ParseClockDuration(raw string, timeDurationFormat string) (time.Duration, error) {
+	parsed, err := time.Parse(timeDurationFormat, raw)
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(
+		parsed.Hour()*int(time.Hour) +
+			parsed.Minute()*int(time.Minute) +
+			parsed.Second()*int(time.Second),
+	), nil
+}
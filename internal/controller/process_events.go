@@ -1,70 +1,85 @@
 package controller
 
 import (
-	"bufio"
 	"fmt"
-	"os"
+	"io"
+	"slices"
 	"sort"
-	"time"
 
+	"github.com/Maksim646/sunny_5_skiers/internal/output"
+	"github.com/Maksim646/sunny_5_skiers/internal/race"
 	"github.com/Maksim646/sunny_5_skiers/model"
 )
 
-func ProcessEvents(events []model.CompetitorEvent, outputFilePath string, timeFormat string) error {
-	outputLogFile, err := os.OpenFile(outputFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
-	if err != nil {
-		return err
+// ProcessEvents applies the events and writes the output log to the file at
+// outputFilePath, see ProcessEventsTo.
+func ProcessEvents(engine *race.Engine, events []model.CompetitorEvent, outputFilePath string, outputFormat string) error {
+	if !slices.Contains(output.LogFormats, outputFormat) {
+		return fmt.Errorf("unknown output format %q", outputFormat)
 	}
-	defer outputLogFile.Close()
 
-	outputLogFileWriter := bufio.NewWriter(outputLogFile)
+	return WriteFile(outputFilePath, func(w io.Writer) error {
+		return ProcessEventsTo(w, engine, events, outputFormat)
+	})
+}
 
-	for _, event := range events {
+// ProcessEventsTo applies the events, finishes the race and writes the output
+// log to w in outputFormat, one of output.LogFormats.
+func ProcessEventsTo(w io.Writer, engine *race.Engine, events []model.CompetitorEvent, outputFormat string) error {
+	if !slices.Contains(output.LogFormats, outputFormat) {
+		return fmt.Errorf("unknown output format %q", outputFormat)
+	}
 
-		comment := formatEventWithComment(event, model.Comments, timeFormat)
+	var entries []output.LogEntry
+	writeEvents := func(outputEvents []model.CompetitorEvent) error {
+		for _, event := range outputEvents {
+			if outputFormat != output.FormatText {
+				entries = append(entries, newLogEntry(engine, event))
+				continue
+			}
+			if _, err := io.WriteString(w, engine.LogLine(event)+"\n"); err != nil {
+				return fmt.Errorf("could not write output log: %w", err)
+			}
+		}
+		return nil
+	}
 
-		_, err := outputLogFileWriter.WriteString(comment + "\n")
+	for _, event := range events {
+		outputEvents, err := engine.Apply(event)
 		if err != nil {
-			return fmt.Errorf("could not write to file: %w", err)
+			return err
 		}
+		if err := writeEvents(outputEvents); err != nil {
+			return err
+		}
+	}
 
+	if err := writeEvents(engine.Finish()); err != nil {
+		return err
 	}
 
-	if err := outputLogFileWriter.Flush(); err != nil {
-		return fmt.Errorf("could not flush buffer: %w", err)
+	var err error
+	switch outputFormat {
+	case output.FormatJSON:
+		err = output.WriteLogJSON(w, entries)
+	case output.FormatCSV:
+		err = output.WriteLogCSV(w, entries)
+	}
+	if err != nil {
+		return fmt.Errorf("could not write output log: %w", err)
 	}
 
 	return nil
 }
 
-func formatEventWithComment(event model.CompetitorEvent, comments map[int]string, timeFormat string) string {
-	timeStr := event.Time.Format(timeFormat)
-	var msg string
-
-	switch event.ID {
-	case model.EventRegistered, model.EventOnTheStartLine, model.EventStart, model.EventLeftFiringRange, model.EventPenaltyLapStart, model.EventPenaltyLapEnd, model.EventLapCompleted:
-		msg = fmt.Sprintf("The competitor(%d) %s", event.Competitor, comments[event.ID])
-	case model.EventStartTimeSet:
-		msg = fmt.Sprintf("The start time for the competitor(%d) was set by a draw to %s", event.Competitor, event.ExtraParams)
-	case model.EventOnTheFiringRange:
-		msg = fmt.Sprintf("The competitor(%d) %s(%s)", event.Competitor, comments[event.ID], event.ExtraParams)
-	case model.EventTargetHit:
-		msg = fmt.Sprintf("The target(%s) has been hit by competitor(%d)", event.ExtraParams, event.Competitor)
-	case model.EventNotFinished:
-		msg = fmt.Sprintf("The competitor(%d) %s: %s", event.Competitor, comments[event.ID], event.ExtraParams)
-	default:
-		msg = fmt.Sprintf("Unknown event ID (%d) for competitor(%d)", event.ID, event.Competitor)
+func newLogEntry(engine *race.Engine, event model.CompetitorEvent) output.LogEntry {
+	return output.LogEntry{
+		Time:        event.Time.Format(engine.TimeFormat()),
+		ID:          event.ID,
+		Competitor:  event.Competitor,
+		ExtraParams: event.ExtraParams,
+		Line:        engine.LogLine(event),
 	}
-
-	return fmt.Sprintf("[%s] %s", timeStr, msg)
-}
-
-func formatDuration(d time.Duration, reportTableTimeFormat string) string {
-	h := int(d.Hours())
-	m := int(d.Minutes()) % 60
-	s := int(d.Seconds()) % 60
-	ms := int(d.Milliseconds()) % 1000
-	return fmt.Sprintf(reportTableTimeFormat, h, m, s, ms)
 }
 
 func SortedEvents(events []model.CompetitorEvent) []model.CompetitorEvent {
@@ -0,0 +1,38 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/Maksim646/sunny_5_skiers/model"
+)
+
+// PursuitStartList draws the start times of a pursuit from the sorted reports
+// of a previous race: the start gaps equal the finishing gaps to the winner,
+// capped at maxGap when it is positive. Competitors without a result are left out.
+func PursuitStartList(reports []model.CompetitorReport, start time.Time, drawTime time.Time, maxGap time.Duration, timeFormat string) []model.CompetitorEvent {
+	var startList []model.CompetitorEvent
+
+	var leaderTime time.Duration
+	for _, report := range reports {
+		if report.Status != model.CompetitorStarted || report.TotalTime == 0 {
+			continue
+		}
+		if len(startList) == 0 {
+			leaderTime = report.TotalTime
+		}
+
+		gap := report.TotalTime - leaderTime
+		if maxGap > 0 && gap > maxGap {
+			gap = maxGap
+		}
+
+		startList = append(startList, model.CompetitorEvent{
+			Time:        drawTime,
+			ID:          model.EventStartTimeSet,
+			Competitor:  report.CompetitorID,
+			ExtraParams: start.Add(gap).Format(timeFormat),
+		})
+	}
+
+	return startList
+}
@@ -0,0 +1,141 @@
+package controller
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Maksim646/sunny_5_skiers/internal/output"
+	"github.com/Maksim646/sunny_5_skiers/model"
+)
+
+// rosterColumns are the columns of a CSV roster, only id is required.
+var rosterColumns = []string{"id", "bib", "name", "nation", "club", "category", "gender"}
+
+// ParseRoster reads a roster from a .json file or, for any other extension, from a CSV file.
+func ParseRoster(path string) (model.Roster, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return ReadRosterJSON(file, path)
+	}
+	return ReadRosterCSV(file, path)
+}
+
+// ReadRosterJSON reads a JSON array of athletes. name is reported in errors.
+func ReadRosterJSON(r io.Reader, name string) (model.Roster, error) {
+	var athletes []model.Athlete
+	if err := json.NewDecoder(r).Decode(&athletes); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	roster := make(model.Roster, len(athletes))
+	for i, athlete := range athletes {
+		if err := addAthlete(roster, athlete); err != nil {
+			return nil, fmt.Errorf("%s: athlete %d: %w", name, i+1, err)
+		}
+	}
+	return roster, nil
+}
+
+// ReadRosterCSV reads a CSV roster with a header line naming its columns.
+// name is reported in parse errors.
+func ReadRosterCSV(r io.Reader, name string) (model.Roster, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return model.Roster{}, nil
+	}
+	if err != nil {
+		return nil, rosterCSVError(name, err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if !slices.Contains(rosterColumns, column) {
+			return nil, &ParseError{File: name, Line: 1, Text: strings.Join(header, ","), Reason: fmt.Sprintf("unknown roster column %q", column)}
+		}
+		columns[column] = i
+	}
+	if _, ok := columns["id"]; !ok {
+		return nil, &ParseError{File: name, Line: 1, Text: strings.Join(header, ","), Reason: "roster has no id column"}
+	}
+
+	roster := model.Roster{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return roster, nil
+		}
+		if err != nil {
+			return nil, rosterCSVError(name, err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		text := strings.Join(record, ",")
+		field := func(column string) string {
+			if i, ok := columns[column]; ok {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		athlete := model.Athlete{
+			Name:     field("name"),
+			Nation:   field("nation"),
+			Club:     field("club"),
+			Category: field("category"),
+			Gender:   field("gender"),
+		}
+		athlete.ID, err = strconv.Atoi(field("id"))
+		if err != nil {
+			return nil, &ParseError{File: name, Line: line, Text: text, Reason: "invalid competitor ID", Err: err}
+		}
+		if bib := field("bib"); bib != "" {
+			athlete.Bib, err = strconv.Atoi(bib)
+			if err != nil {
+				return nil, &ParseError{File: name, Line: line, Text: text, Reason: "invalid bib", Err: err}
+			}
+		}
+
+		if err := addAthlete(roster, athlete); err != nil {
+			return nil, &ParseError{File: name, Line: line, Text: text, Reason: err.Error()}
+		}
+	}
+}
+
+func addAthlete(roster model.Roster, athlete model.Athlete) error {
+	if athlete.ID <= 0 {
+		return fmt.Errorf("competitor ID must be positive, got %d", athlete.ID)
+	}
+	if athlete.Category == output.OverallTitle {
+		return fmt.Errorf("category %q is reserved for the overall ranking", athlete.Category)
+	}
+	if _, ok := roster[athlete.ID]; ok {
+		return fmt.Errorf("duplicate competitor ID %d", athlete.ID)
+	}
+	roster[athlete.ID] = athlete
+	return nil
+}
+
+func rosterCSVError(name string, err error) error {
+	var csvErr *csv.ParseError
+	if errors.As(err, &csvErr) {
+		return &ParseError{File: name, Line: csvErr.Line, Reason: "invalid CSV", Err: csvErr.Err}
+	}
+	return err
+}
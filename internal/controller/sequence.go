@@ -0,0 +1,273 @@
+package controller
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Maksim646/sunny_5_skiers/internal/race"
+	"github.com/Maksim646/sunny_5_skiers/model"
+	"go.uber.org/zap"
+)
+
+// SequenceError describes an event that breaks the chronological order of the
+// stream or the lifecycle of its competitor.
+type SequenceError struct {
+	Event  model.CompetitorEvent
+	Reason string
+	// TimeFormat is the event time format the time is written in.
+	TimeFormat string
+}
+
+func (e *SequenceError) Error() string {
+	return fmt.Sprintf("event %d for competitor(%d) at %s: %s",
+		e.Event.ID, e.Event.Competitor, e.Event.Time.Format(e.TimeFormat), e.Reason)
+}
+
+type stage int
+
+const (
+	stageNone stage = iota
+	stageRegistered
+	stageDrawn
+	stageOnStartLine
+	stageRunning
+	stageOnFiringRange
+	stagePenalty
+	stageFinished
+	stageRetired
+	stageDisqualified
+)
+
+type lifecycle struct {
+	stage      stage
+	laps       int
+	hitTargets map[int]bool
+}
+
+// SequenceValidator checks events one at a time. In strict mode the first
+// violation is returned as a *SequenceError, otherwise it is logged as a
+// warning and the event is accepted.
+type SequenceValidator struct {
+	config            model.Config
+	timeFormat        string
+	targetsInFireLine int
+	strict            bool
+	// penaltyLoops is false for formats with a time penalty, which have no penalty laps.
+	penaltyLoops bool
+
+	lastTime    time.Time
+	seen        bool
+	competitors map[int]*lifecycle
+}
+
+func NewSequenceValidator(config model.Config, timeFormat string, targetsInFireLine int, strict bool) *SequenceValidator {
+	return &SequenceValidator{
+		config:            config,
+		timeFormat:        timeFormat,
+		targetsInFireLine: targetsInFireLine,
+		strict:            strict,
+		penaltyLoops:      race.NewRules(config).HasPenaltyLoops(),
+		competitors:       make(map[int]*lifecycle),
+	}
+}
+
+func ValidateSequence(events []model.CompetitorEvent, config model.Config, timeFormat string, targetsInFireLine int, strict bool) error {
+	validator := NewSequenceValidator(config, timeFormat, targetsInFireLine, strict)
+	for _, event := range events {
+		if err := validator.Check(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Check validates the event against the events seen so far and records it
+// unless it is rejected.
+func (v *SequenceValidator) Check(event model.CompetitorEvent) error {
+	if err := v.Validate(event); err != nil {
+		return err
+	}
+	v.Record(event)
+	return nil
+}
+
+// Validate checks the event against the events recorded so far without
+// recording it, so a rejected event leaves the validator as it was.
+func (v *SequenceValidator) Validate(event model.CompetitorEvent) error {
+	reason := v.violation(event)
+	if reason == "" {
+		return nil
+	}
+
+	err := &SequenceError{Event: event, Reason: reason, TimeFormat: v.timeFormat}
+	if v.strict {
+		return err
+	}
+	zap.L().Warn("event out of sequence", zap.Error(err))
+	return nil
+}
+
+func (v *SequenceValidator) violation(event model.CompetitorEvent) string {
+	if v.seen && event.Time.Before(v.lastTime) {
+		return fmt.Sprintf("event goes back in time, previous event at %s", v.lastTime.Format(v.timeFormat))
+	}
+
+	c := v.lifecycle(event.Competitor)
+
+	if c.stage == stageNone && event.ID != model.EventRegistered {
+		return "competitor is not registered"
+	}
+	if c.stage == stageDisqualified {
+		return "competitor is disqualified"
+	}
+	// The jury may disqualify a competitor after the race.
+	if (c.stage == stageFinished || c.stage == stageRetired) && event.ID != model.EventJuryDisqualified {
+		return "competitor has already left the race"
+	}
+
+	if !v.penaltyLoops && (event.ID == model.EventPenaltyLapStart || event.ID == model.EventPenaltyLapEnd) {
+		return "penalty laps in a format with a time penalty"
+	}
+
+	switch event.ID {
+	case model.EventRegistered:
+		if c.stage != stageNone {
+			return "competitor is already registered"
+		}
+		if _, ok := v.config.Roster[event.Competitor]; v.config.Roster != nil && !ok {
+			return "competitor is not on the roster"
+		}
+	case model.EventStartTimeSet:
+		if c.stage == stageDrawn {
+			return "start time is already drawn"
+		}
+		if c.stage > stageDrawn {
+			return "start time drawn after the competitor came to the start line"
+		}
+	case model.EventOnTheStartLine:
+		if c.stage < stageDrawn {
+			return "on the start line before the start time draw"
+		}
+		if c.stage > stageDrawn {
+			return "competitor is already on the start line"
+		}
+	case model.EventStart:
+		if c.stage < stageDrawn {
+			return "started before the start time draw"
+		}
+		if c.stage == stageDrawn {
+			return "started without coming to the start line"
+		}
+		if c.stage > stageOnStartLine {
+			return "competitor has already started"
+		}
+	case model.EventOnTheFiringRange:
+		if reason := v.requireRunning(c, "entered a firing range"); reason != "" {
+			return reason
+		}
+	case model.EventTargetHit:
+		if c.stage != stageOnFiringRange {
+			return "target hit outside a firing range"
+		}
+		target, err := strconv.Atoi(event.ExtraParams)
+		if err != nil {
+			return ""
+		}
+		if target > v.targetsInFireLine {
+			return fmt.Sprintf("target %d is above the %d targets in the fire line", target, v.targetsInFireLine)
+		}
+		if c.hitTargets[target] {
+			return fmt.Sprintf("target %d is already hit", target)
+		}
+	case model.EventLeftFiringRange:
+		if c.stage != stageOnFiringRange {
+			return "left a firing range without entering it"
+		}
+	case model.EventPenaltyLapStart:
+		if reason := v.requireRunning(c, "entered the penalty laps"); reason != "" {
+			return reason
+		}
+	case model.EventPenaltyLapEnd:
+		if c.stage != stagePenalty {
+			return "left the penalty laps without entering them"
+		}
+	case model.EventLapCompleted:
+		if reason := v.requireRunning(c, "ended a lap"); reason != "" {
+			return reason
+		}
+	case model.EventLapped:
+		if reason := v.requireRunning(c, "lapped"); reason != "" {
+			return reason
+		}
+	}
+
+	return ""
+}
+
+func (v *SequenceValidator) requireRunning(c *lifecycle, action string) string {
+	switch c.stage {
+	case stageRunning:
+		return ""
+	case stageOnFiringRange:
+		return action + " while on a firing range"
+	case stagePenalty:
+		return action + " while in the penalty laps"
+	default:
+		return action + " before the start"
+	}
+}
+
+// Record moves the competitor to the stage an accepted event leads to, so
+// that a single violation in lenient mode does not cascade into more warnings.
+func (v *SequenceValidator) Record(event model.CompetitorEvent) {
+	if !v.seen || event.Time.After(v.lastTime) {
+		v.lastTime = event.Time
+		v.seen = true
+	}
+
+	c := v.lifecycle(event.Competitor)
+
+	switch event.ID {
+	case model.EventRegistered:
+		c.stage = stageRegistered
+		if v.config.Format == model.FormatMassStart {
+			// Everyone starts together at Config.Start, there is no draw.
+			c.stage = stageDrawn
+		}
+	case model.EventStartTimeSet:
+		c.stage = stageDrawn
+	case model.EventOnTheStartLine:
+		c.stage = stageOnStartLine
+	case model.EventStart, model.EventLeftFiringRange, model.EventPenaltyLapEnd:
+		c.stage = stageRunning
+	case model.EventOnTheFiringRange:
+		c.stage = stageOnFiringRange
+		c.hitTargets = make(map[int]bool)
+	case model.EventTargetHit:
+		if target, err := strconv.Atoi(event.ExtraParams); err == nil && c.hitTargets != nil {
+			c.hitTargets[target] = true
+		}
+	case model.EventPenaltyLapStart:
+		c.stage = stagePenalty
+	case model.EventLapCompleted:
+		c.stage = stageRunning
+		c.laps++
+		if c.laps >= v.config.Laps {
+			c.stage = stageFinished
+		}
+	case model.EventNotFinished, model.EventLapped:
+		c.stage = stageRetired
+	case model.EventJuryDisqualified:
+		c.stage = stageDisqualified
+	}
+}
+
+func (v *SequenceValidator) lifecycle(competitorID int) *lifecycle {
+	c, ok := v.competitors[competitorID]
+	if !ok {
+		c = &lifecycle{}
+		v.competitors[competitorID] = c
+	}
+	return c
+}
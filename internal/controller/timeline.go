@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/Maksim646/sunny_5_skiers/model"
+)
+
+// rolloverGap is how far the clock has to go back for the next event to be
+// taken as the day after. Smaller steps back are left to the sequence
+// validator.
+const rolloverGap = 12 * time.Hour
+
+// Timeline places the clock times of events on the race date. A clock that
+// goes back by more than rolloverGap moves the timeline to the next day, an
+// event with a full date moves it to that date.
+type Timeline struct {
+	day  time.Time
+	last time.Time
+	seen bool
+}
+
+// NewTimeline starts a timeline on date, the midnight of the first race day.
+// A zero date keeps the day of clock only times, 0000-01-01 UTC.
+func NewTimeline(date time.Time) *Timeline {
+	if date.IsZero() {
+		date = time.Date(0, time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return &Timeline{day: date}
+}
+
+// Place returns the event with its time on the current race day.
+func (t *Timeline) Place(event model.CompetitorEvent) model.CompetitorEvent {
+	if event.Time.Year() != 0 {
+		if event.Time.Location() == time.UTC {
+			// No UTC offset was given, the time is in the race time zone.
+			event.Time = onDay(event.Time, event.Time, t.day.Location())
+		}
+		t.day = onDay(event.Time, time.Time{}, event.Time.Location())
+	} else {
+		placed := onDay(t.day, event.Time, t.day.Location())
+		if t.seen && t.last.Sub(placed) > rolloverGap {
+			t.day = t.day.AddDate(0, 0, 1)
+			placed = placed.AddDate(0, 0, 1)
+		}
+		event.Time = placed
+	}
+
+	t.last = event.Time
+	t.seen = true
+	return event
+}
+
+// PlaceEvents places the clock times of events on the race date, in order.
+func PlaceEvents(events []model.CompetitorEvent, date time.Time) []model.CompetitorEvent {
+	timeline := NewTimeline(date)
+	placed := make([]model.CompetitorEvent, len(events))
+	for i, event := range events {
+		placed[i] = timeline.Place(event)
+	}
+	return placed
+}
+
+// onDay returns the wall clock time of clock on the date of day in location.
+func onDay(day time.Time, clock time.Time, location *time.Location) time.Time {
+	year, month, date := day.Date()
+	return time.Date(year, month, date, clock.Hour(), clock.Minute(), clock.Second(), clock.Nanosecond(), location)
+}
@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Maksim646/sunny_5_skiers/model"
+)
+
+type paramKind int
+
+const (
+	paramNone paramKind = iota
+	paramTime
+	paramNumber
+	paramText
+)
+
+// eventSchema describes the extra params an incoming event must carry.
+type eventSchema struct {
+	param paramKind
+	name  string
+}
+
+var eventSchemas = map[int]eventSchema{
+	model.EventRegistered:       {param: paramNone},
+	model.EventStartTimeSet:     {param: paramTime, name: "start time"},
+	model.EventOnTheStartLine:   {param: paramNone},
+	model.EventStart:            {param: paramNone},
+	model.EventOnTheFiringRange: {param: paramNumber, name: "firing range"},
+	model.EventTargetHit:        {param: paramNumber, name: "target"},
+	model.EventLeftFiringRange:  {param: paramNone},
+	model.EventPenaltyLapStart:  {param: paramNone},
+	model.EventPenaltyLapEnd:    {param: paramNone},
+	model.EventLapCompleted:     {param: paramNone},
+	model.EventNotFinished:      {param: paramText, name: "comment"},
+	model.EventJuryDisqualified: {param: paramText, name: "reason"},
+	model.EventLapped:           {param: paramNone},
+}
+
+// ValidateEvent checks an incoming event against the schema of its event ID.
+func ValidateEvent(event model.CompetitorEvent, timeFormat string) error {
+	if event.Competitor <= 0 {
+		return fmt.Errorf("competitor ID must be positive, got %d", event.Competitor)
+	}
+
+	schema, ok := eventSchemas[event.ID]
+	if !ok {
+		if event.ID == model.EventDisqualified || event.ID == model.EventFinished {
+			return fmt.Errorf("event %d is outgoing and cannot be submitted", event.ID)
+		}
+		return fmt.Errorf("unknown event ID %d", event.ID)
+	}
+
+	switch schema.param {
+	case paramNone:
+		if event.ExtraParams != "" {
+			return fmt.Errorf("event %d takes no extra params, got %q", event.ID, event.ExtraParams)
+		}
+	case paramTime:
+		if event.ExtraParams == "" {
+			return fmt.Errorf("event %d requires a %s", event.ID, schema.name)
+		}
+		if _, err := time.Parse(timeFormat, event.ExtraParams); err != nil {
+			return fmt.Errorf("event %d has invalid %s %q", event.ID, schema.name, event.ExtraParams)
+		}
+	case paramNumber:
+		if event.ExtraParams == "" {
+			return fmt.Errorf("event %d requires a %s number", event.ID, schema.name)
+		}
+		number, err := strconv.Atoi(event.ExtraParams)
+		if err != nil || number <= 0 {
+			return fmt.Errorf("event %d has invalid %s number %q", event.ID, schema.name, event.ExtraParams)
+		}
+	case paramText:
+		if event.ExtraParams == "" {
+			return fmt.Errorf("event %d requires a %s", event.ID, schema.name)
+		}
+	}
+
+	return nil
+}
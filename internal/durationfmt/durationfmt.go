@@ -0,0 +1,374 @@
+// Package durationfmt formats and parses durations with named layout tokens.
+//
+// A layout is made of the tokens
+//
+//	h, hh   hours, hh is padded to two digits
+//	m, mm   minutes
+//	s, ss   seconds
+//	f...    fractions of a second, one digit per f up to nine, truncated
+//	[...]   an optional section, written only when one of its fields is not
+//	        zero or an earlier optional section was written
+//	\x      the literal character x
+//
+// and literal text. The largest unit of the layout is not wrapped, e.g. 65
+// minutes are written as 65:00.0 with the layout m:ss.f, and units cannot be
+// skipped, hh:ss is rejected. The usual layouts
+// are hh:mm:ss.fff and [h:]mm:ss.f, the latter writes hours only when there
+// are any. Negative durations are written with a leading minus sign.
+package durationfmt
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type kind int
+
+// The field kinds are ordered from the largest unit to the smallest.
+const (
+	hours kind = iota
+	minutes
+	seconds
+	fraction
+	literal
+	optional
+)
+
+var fieldNames = map[kind]string{
+	hours:    "hours",
+	minutes:  "minutes",
+	seconds:  "seconds",
+	fraction: "fraction",
+}
+
+type element struct {
+	kind kind
+	// width is the number of digits of a field, the minimal one for hours,
+	// minutes and seconds.
+	width int
+	text  string
+	// elements are the contents of an optional section.
+	elements []element
+}
+
+// Layout is a compiled duration layout.
+type Layout struct {
+	layout   string
+	elements []element
+	largest  kind
+}
+
+// Compile checks a layout and prepares it for formatting and parsing.
+func Compile(layout string) (Layout, error) {
+	if strings.Contains(layout, "%") {
+		return Layout{}, fmt.Errorf("duration layout %q uses printf verbs, use tokens such as hh:mm:ss.fff", layout)
+	}
+
+	elements, rest, err := compile(layout, false)
+	if err != nil {
+		return Layout{}, fmt.Errorf("duration layout %q: %w", layout, err)
+	}
+	if rest != "" {
+		return Layout{}, fmt.Errorf("duration layout %q: unexpected ]", layout)
+	}
+
+	seen := make(map[kind]bool)
+	if err := collectFields(elements, seen); err != nil {
+		return Layout{}, fmt.Errorf("duration layout %q: %w", layout, err)
+	}
+
+	largest := fraction
+	for _, k := range []kind{seconds, minutes, hours} {
+		if seen[k] {
+			largest = k
+		}
+	}
+	if largest == fraction {
+		return Layout{}, fmt.Errorf("duration layout %q has no hours, minutes or seconds", layout)
+	}
+
+	// A skipped unit would be dropped from the duration, e.g. the minutes of
+	// hh:ss, so the fields must run from the largest unit down without gaps.
+	for k := largest + 1; k <= fraction; k++ {
+		if !seen[k-1] && seen[k] {
+			return Layout{}, fmt.Errorf("duration layout %q has %s without %s", layout, fieldNames[k], fieldNames[k-1])
+		}
+	}
+
+	return Layout{layout: layout, elements: elements, largest: largest}, nil
+}
+
+// MustCompile is like Compile but panics on an invalid layout.
+func MustCompile(layout string) Layout {
+	l, err := Compile(layout)
+	if err != nil {
+		panic(err)
+	}
+	return l
+}
+
+// Format writes d with the layout.
+func Format(d time.Duration, layout string) (string, error) {
+	l, err := Compile(layout)
+	if err != nil {
+		return "", err
+	}
+	return l.Format(d), nil
+}
+
+// Parse reads a duration written with the layout.
+func Parse(layout string, value string) (time.Duration, error) {
+	l, err := Compile(layout)
+	if err != nil {
+		return 0, err
+	}
+	return l.Parse(value)
+}
+
+// compile reads elements up to the end of the layout or, inside a section,
+// up to its closing bracket. rest is the layout after the bracket.
+func compile(layout string, inSection bool) (elements []element, rest string, err error) {
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			elements = append(elements, element{kind: literal, text: text.String()})
+			text.Reset()
+		}
+	}
+
+	for len(layout) > 0 {
+		c := layout[0]
+		switch c {
+		case 'h', 'm', 's', 'f':
+			flush()
+			width := len(layout) - len(strings.TrimLeft(layout, string(c)))
+			k := map[byte]kind{'h': hours, 'm': minutes, 's': seconds, 'f': fraction}[c]
+			if k == fraction && width > 9 {
+				return nil, "", fmt.Errorf("at most nine fraction digits")
+			}
+			if k != fraction && width > 2 {
+				return nil, "", fmt.Errorf("%s take one or two letters", fieldNames[k])
+			}
+			elements = append(elements, element{kind: k, width: width})
+			layout = layout[width:]
+		case '[':
+			if inSection {
+				return nil, "", fmt.Errorf("nested optional section")
+			}
+			flush()
+			section, rest, err := compile(layout[1:], true)
+			if err != nil {
+				return nil, "", err
+			}
+			elements = append(elements, element{kind: optional, elements: section})
+			layout = rest
+		case ']':
+			if !inSection {
+				return elements, layout, nil
+			}
+			flush()
+			return elements, layout[1:], nil
+		case '\\':
+			if len(layout) < 2 {
+				return nil, "", fmt.Errorf("trailing backslash")
+			}
+			text.WriteByte(layout[1])
+			layout = layout[2:]
+		default:
+			text.WriteByte(c)
+			layout = layout[1:]
+		}
+	}
+
+	if inSection {
+		return nil, "", fmt.Errorf("unclosed optional section")
+	}
+	flush()
+	return elements, "", nil
+}
+
+// collectFields makes sure every unit appears once and every optional
+// section has a field.
+func collectFields(elements []element, seen map[kind]bool) error {
+	for _, e := range elements {
+		switch e.kind {
+		case literal:
+		case optional:
+			fields := len(seen)
+			if err := collectFields(e.elements, seen); err != nil {
+				return err
+			}
+			if len(seen) == fields {
+				return fmt.Errorf("optional section without a field")
+			}
+		default:
+			if seen[e.kind] {
+				return fmt.Errorf("%s appear twice", fieldNames[e.kind])
+			}
+			seen[e.kind] = true
+		}
+	}
+	return nil
+}
+
+// String returns the layout as it was compiled.
+func (l Layout) String() string {
+	return l.layout
+}
+
+// parts are the values of the fields.
+type parts map[kind]int64
+
+// Format writes d with the layout.
+func (l Layout) Format(d time.Duration) string {
+	var sb strings.Builder
+	if d < 0 {
+		sb.WriteByte('-')
+		d = -d
+	}
+
+	p := l.split(d)
+	sectionWritten := false
+	var write func(elements []element)
+	write = func(elements []element) {
+		for _, e := range elements {
+			switch e.kind {
+			case literal:
+				sb.WriteString(e.text)
+			case optional:
+				if sectionWritten || p.anyNonZero(e.elements) {
+					sectionWritten = true
+					write(e.elements)
+				}
+			default:
+				fmt.Fprintf(&sb, "%0*d", e.width, p[e.kind]/fractionDivisor(e))
+			}
+		}
+	}
+	write(l.elements)
+
+	return sb.String()
+}
+
+// split breaks d into the fields, the largest one takes the remainder.
+func (l Layout) split(d time.Duration) parts {
+	p := parts{fraction: int64(d % time.Second)}
+	units := map[kind]time.Duration{hours: time.Hour, minutes: time.Minute, seconds: time.Second}
+	rest := d - d%time.Second
+	for _, k := range []kind{hours, minutes, seconds} {
+		if k < l.largest {
+			continue
+		}
+		p[k] = int64(rest / units[k])
+		rest %= units[k]
+	}
+	return p
+}
+
+func (p parts) anyNonZero(elements []element) bool {
+	for _, e := range elements {
+		if e.kind != literal && p[e.kind]/fractionDivisor(e) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// fractionDivisor brings nanoseconds to the digits of a fraction field, it
+// is 1 for the other fields.
+func fractionDivisor(e element) int64 {
+	if e.kind != fraction {
+		return 1
+	}
+	divisor := int64(1)
+	for i := e.width; i < 9; i++ {
+		divisor *= 10
+	}
+	return divisor
+}
+
+// Parse reads a duration written with the layout. The fields of a missing
+// optional section are zero.
+func (l Layout) Parse(value string) (time.Duration, error) {
+	negative := strings.HasPrefix(value, "-")
+	p, err := l.match(l.elements, strings.TrimPrefix(value, "-"), parts{}, false)
+	if err != nil {
+		return 0, fmt.Errorf("cannot parse %q as %q: %w", value, l.layout, err)
+	}
+
+	d := time.Duration(p[hours])*time.Hour +
+		time.Duration(p[minutes])*time.Minute +
+		time.Duration(p[seconds])*time.Second +
+		time.Duration(p[fraction])
+	if negative {
+		d = -d
+	}
+	return d, nil
+}
+
+// match reads the elements from value, it tries every optional section with
+// and then without its contents. Once a section is matched the later ones
+// are required, as Format writes them.
+func (l Layout) match(elements []element, value string, p parts, sectionMatched bool) (parts, error) {
+	if len(elements) == 0 {
+		if value != "" {
+			return nil, fmt.Errorf("unexpected %q", value)
+		}
+		return p, nil
+	}
+
+	e, rest := elements[0], elements[1:]
+	switch e.kind {
+	case literal:
+		if !strings.HasPrefix(value, e.text) {
+			return nil, fmt.Errorf("expected %q", e.text)
+		}
+		return l.match(rest, value[len(e.text):], p, sectionMatched)
+	case optional:
+		with := append(append([]element(nil), e.elements...), rest...)
+		matched, err := l.match(with, value, p.clone(), true)
+		if err == nil || sectionMatched {
+			return matched, err
+		}
+		return l.match(rest, value, p, false)
+	}
+
+	digits := 0
+	for digits < len(value) && value[digits] >= '0' && value[digits] <= '9' {
+		digits++
+	}
+	switch {
+	case e.kind == fraction || (e.kind != l.largest && e.width == 2):
+		// Fixed width fields are followed by anything, even more digits.
+		if digits < e.width {
+			return nil, fmt.Errorf("%s needs %d digits", fieldNames[e.kind], e.width)
+		}
+		digits = e.width
+	case digits < e.width:
+		return nil, fmt.Errorf("%s needs at least %d digits", fieldNames[e.kind], e.width)
+	}
+
+	var n int64
+	for _, c := range value[:digits] {
+		n = n*10 + int64(c-'0')
+	}
+	if e.kind != l.largest && e.kind != fraction && e.kind != hours && n >= 60 {
+		return nil, fmt.Errorf("%s out of range", fieldNames[e.kind])
+	}
+	if e.kind == fraction {
+		n *= fractionDivisor(e)
+	}
+
+	p = p.clone()
+	p[e.kind] = n
+	return l.match(rest, value[digits:], p, sectionMatched)
+}
+
+func (p parts) clone() parts {
+	cloned := make(parts, len(p))
+	for k, v := range p {
+		cloned[k] = v
+	}
+	return cloned
+}
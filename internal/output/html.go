@@ -0,0 +1,219 @@
+package output
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Maksim646/sunny_5_skiers/internal/durationfmt"
+	"github.com/Maksim646/sunny_5_skiers/model"
+)
+
+//go:embed results.html.tmpl
+var resultsHTML string
+
+var resultsTemplate = template.Must(template.New("results").Parse(resultsHTML))
+
+type htmlPage struct {
+	Title       string
+	Laps        []int
+	FiringLines []int
+	Sections    []htmlSection
+}
+
+// htmlSection is a ranked table, the overall ranking or the one of a category.
+type htmlSection struct {
+	Title string
+	Rows  []htmlRow
+}
+
+type htmlRow struct {
+	Place        string
+	CompetitorID int
+	Bib          string
+	Name         string
+	Team         string
+	Result       string
+	Reason       string
+	Gap          string
+	Finished     bool
+	Laps         []htmlLap
+	PenaltyLoops string
+	PenaltyTime  string
+	Shooting     []htmlShooting
+	Hits         int
+	Shots        int
+
+	gapToLeader time.Duration
+}
+
+type htmlLap struct {
+	Time  string
+	Speed string
+	Split string
+}
+
+type htmlShooting struct {
+	FiringRange int
+	Position    string
+	Grid        string
+	Time        string
+}
+
+// WriteResultsHTML writes the reports as a single HTML page with inline
+// styles. Reports are expected in the order of the result table. The page
+// has the overall ranking and, if any competitor has a category, a ranking
+// per category, gaps are measured from the leader of each ranking.
+func WriteResultsHTML(w io.Writer, reports []model.CompetitorReport, config model.Config, timeLayout durationfmt.Layout) error {
+	laps, firingLines := config.Laps, config.FiringLines
+	for _, report := range reports {
+		laps = max(laps, len(report.Laps))
+		firingLines = max(firingLines, len(report.FiringRanges))
+	}
+
+	page := htmlPage{
+		Title:       fmt.Sprintf("Results, %s", formatName(config.Format)),
+		Laps:        numbers(laps),
+		FiringLines: numbers(firingLines),
+	}
+
+	overall := htmlSection{}
+	for _, report := range reports {
+		overall.Rows = append(overall.Rows, newHTMLRow(report, report.Place, laps, firingLines, timeLayout))
+	}
+	categories := Categories(reports)
+	if len(categories) > 0 {
+		overall.Title = OverallTitle
+	}
+	page.Sections = append(page.Sections, overall)
+
+	for _, category := range categories {
+		section := htmlSection{Title: category}
+		for _, report := range InCategory(reports, category) {
+			section.Rows = append(section.Rows, newHTMLRow(report, report.CategoryPlace, laps, firingLines, timeLayout))
+		}
+		page.Sections = append(page.Sections, section)
+	}
+
+	for _, section := range page.Sections {
+		setGaps(section.Rows, timeLayout)
+	}
+
+	return resultsTemplate.Execute(w, page)
+}
+
+func newHTMLRow(report model.CompetitorReport, place int, laps int, firingLines int, timeLayout durationfmt.Layout) htmlRow {
+	row := htmlRow{
+		CompetitorID: report.CompetitorID,
+		Bib:          formatBib(report.Athlete.Bib),
+		Name:         report.Athlete.Name,
+		Team:         team(report.Athlete),
+		Result:       report.Status.Abbreviation(),
+		Reason:       report.Reason,
+		Hits:         report.Hits,
+		Shots:        report.Shots,
+		gapToLeader:  report.GapToLeader,
+	}
+
+	if report.Status == model.CompetitorStarted {
+		row.Finished = true
+		row.Result = timeLayout.Format(report.TotalTime)
+	}
+	if place > 0 {
+		row.Place = fmt.Sprint(place)
+	}
+
+	for i := 0; i < laps; i++ {
+		if i < len(report.Laps) {
+			row.Laps = append(row.Laps, htmlLap{
+				Time:  timeLayout.Format(report.Laps[i].Time),
+				Speed: fmt.Sprintf("%.3f", report.Laps[i].Speed),
+				Split: fmt.Sprintf("%s (%d)", timeLayout.Format(report.Laps[i].Split), report.Laps[i].SplitRank),
+			})
+		} else {
+			row.Laps = append(row.Laps, htmlLap{})
+		}
+	}
+
+	if report.PenaltyLoops > 0 || report.PenaltyLoopsRun > 0 {
+		row.PenaltyLoops = fmt.Sprintf("%d/%d", report.PenaltyLoopsRun, report.PenaltyLoops)
+	}
+	if report.PenaltyLoopsRun > 0 {
+		row.PenaltyTime = timeLayout.Format(report.PenaltyTime)
+	}
+	if report.TimePenalty > 0 {
+		row.PenaltyTime = "+" + timeLayout.Format(report.TimePenalty)
+	}
+
+	for i := 0; i < firingLines; i++ {
+		if i < len(report.FiringRanges) {
+			visit := report.FiringRanges[i]
+			row.Shooting = append(row.Shooting, htmlShooting{
+				FiringRange: visit.FiringRange,
+				Position:    visit.Position,
+				Grid:        shootingGrid(visit),
+				Time:        timeLayout.Format(visit.Time),
+			})
+		} else {
+			row.Shooting = append(row.Shooting, htmlShooting{})
+		}
+	}
+
+	return row
+}
+
+// setGaps writes the gap to the first finisher of the rows for every finisher
+// behind it. Gaps are taken from the reports, so they are measured at the
+// ranking resolution like in the other formats.
+func setGaps(rows []htmlRow, timeLayout durationfmt.Layout) {
+	var leaderGap time.Duration
+	leaderFound := false
+	for i := range rows {
+		if !rows[i].Finished {
+			continue
+		}
+		if !leaderFound {
+			leaderGap = rows[i].gapToLeader
+			leaderFound = true
+		}
+		if gap := rows[i].gapToLeader - leaderGap; gap > 0 {
+			rows[i].Gap = "+" + timeLayout.Format(gap)
+		}
+	}
+}
+
+// shootingGrid draws every target of a visit, ● for a hit and ○ for a miss.
+func shootingGrid(visit model.FiringRangeVisit) string {
+	mask := HitMask(visit)
+	return strings.NewReplacer("1", "●", "0", "○").Replace(mask)
+}
+
+// team joins the nation and the club of an athlete.
+func team(athlete model.Athlete) string {
+	switch {
+	case athlete.Nation != "" && athlete.Club != "":
+		return athlete.Nation + ", " + athlete.Club
+	case athlete.Nation != "":
+		return athlete.Nation
+	default:
+		return athlete.Club
+	}
+}
+
+func formatName(format string) string {
+	if format == "" {
+		return model.FormatSprint
+	}
+	return format
+}
+
+func numbers(n int) []int {
+	list := make([]int, n)
+	for i := range list {
+		list[i] = i + 1
+	}
+	return list
+}
@@ -0,0 +1,258 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Maksim646/sunny_5_skiers/model"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+	FormatHTML = "html"
+)
+
+// Formats lists the formats of the result table.
+var Formats = []string{FormatText, FormatJSON, FormatCSV, FormatHTML}
+
+// LogFormats lists the formats of the output log.
+var LogFormats = []string{FormatText, FormatJSON, FormatCSV}
+
+// LogFormatFor returns the output log format written next to a result table
+// in format. There is no html log, an html table comes with a text log.
+func LogFormatFor(format string) string {
+	if format == FormatHTML {
+		return FormatText
+	}
+	return format
+}
+
+// WriteResultsJSON writes the reports as a Results document.
+func WriteResultsJSON(w io.Writer, reports []model.CompetitorReport) error {
+	return writeJSON(w, Results{SchemaVersion: SchemaVersion, Results: NewCompetitors(reports)})
+}
+
+// WriteLogJSON writes the log entries as a Log document.
+func WriteLogJSON(w io.Writer, entries []LogEntry) error {
+	if entries == nil {
+		entries = []LogEntry{}
+	}
+	return writeJSON(w, Log{SchemaVersion: SchemaVersion, Events: entries})
+}
+
+// WriteResultsCSV writes one row per competitor. Every lap and every firing
+// line gets its own columns, competitors with fewer of them leave the cells empty.
+func WriteResultsCSV(w io.Writer, reports []model.CompetitorReport, config model.Config) error {
+	laps, firingLines := config.Laps, config.FiringLines
+	for _, report := range reports {
+		laps = max(laps, len(report.Laps))
+		firingLines = max(firingLines, len(report.FiringRanges))
+	}
+
+	header := []string{"place", "competitorId", "bib", "name", "nation", "club", "category", "categoryPlace", "gender", "status", "reason", "totalTimeMs", "gapToLeaderMs", "gapToAheadMs", "startLagMs"}
+	for i := 1; i <= laps; i++ {
+		header = append(header,
+			fmt.Sprintf("lap%dTimeMs", i),
+			fmt.Sprintf("lap%dSpeed", i),
+			fmt.Sprintf("lap%dSplitMs", i),
+			fmt.Sprintf("lap%dSplitRank", i),
+		)
+	}
+	header = append(header, "penaltyLoops", "penaltyLoopsRun", "missedPenaltyLoops", "penaltyTimeMs", "penaltySpeed", "timePenaltyMs", "hits", "shots")
+	for i := 1; i <= firingLines; i++ {
+		header = append(header,
+			fmt.Sprintf("range%dFiringRange", i),
+			fmt.Sprintf("range%dPosition", i),
+			fmt.Sprintf("range%dHits", i),
+			fmt.Sprintf("range%dTimeMs", i),
+		)
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, report := range reports {
+		row := []string{
+			formatPlace(report.Place),
+			strconv.Itoa(report.CompetitorID),
+			formatBib(report.Athlete.Bib),
+			report.Athlete.Name,
+			report.Athlete.Nation,
+			report.Athlete.Club,
+			report.Category,
+			formatPlace(report.CategoryPlace),
+			report.Athlete.Gender,
+			string(report.Status),
+			report.Reason,
+			formatMs(report.TotalTime.Milliseconds()),
+			formatMs(report.GapToLeader.Milliseconds()),
+			formatMs(report.GapToAhead.Milliseconds()),
+			formatMs(report.StartLag.Milliseconds()),
+		}
+		for i := 0; i < laps; i++ {
+			if i < len(report.Laps) {
+				lap := report.Laps[i]
+				row = append(row, formatMs(lap.Time.Milliseconds()), formatSpeed(lap.Speed), formatMs(lap.Split.Milliseconds()), strconv.Itoa(lap.SplitRank))
+			} else {
+				row = append(row, "", "", "", "")
+			}
+		}
+		row = append(row,
+			strconv.Itoa(report.PenaltyLoops),
+			strconv.Itoa(report.PenaltyLoopsRun),
+			strconv.Itoa(report.MissedPenaltyLoops),
+			formatMs(report.PenaltyTime.Milliseconds()),
+			formatSpeed(report.PenaltySpeed),
+			formatMs(report.TimePenalty.Milliseconds()),
+			strconv.Itoa(report.Hits),
+			strconv.Itoa(report.Shots),
+		)
+		for i := 0; i < firingLines; i++ {
+			if i < len(report.FiringRanges) {
+				visit := report.FiringRanges[i]
+				row = append(row, strconv.Itoa(visit.FiringRange), visit.Position, HitMask(visit), formatMs(visit.Time.Milliseconds()))
+			} else {
+				row = append(row, "", "", "", "")
+			}
+		}
+
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteLogCSV writes one row per log entry.
+func WriteLogCSV(w io.Writer, entries []LogEntry) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"time", "id", "competitor", "extraParams", "line"}); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		row := []string{entry.Time, strconv.Itoa(entry.ID), strconv.Itoa(entry.Competitor), entry.ExtraParams, entry.Line}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// OverallTitle heads the overall ranking when there are category rankings,
+// no category may have this name.
+const OverallTitle = "Overall"
+
+// Categories returns the categories of the reports in alphabetical order.
+func Categories(reports []model.CompetitorReport) []string {
+	var categories []string
+	for _, report := range reports {
+		if report.Category != "" && !slices.Contains(categories, report.Category) {
+			categories = append(categories, report.Category)
+		}
+	}
+	slices.Sort(categories)
+	return categories
+}
+
+// InCategory returns the reports of a category in their original order.
+func InCategory(reports []model.CompetitorReport, category string) []model.CompetitorReport {
+	var inCategory []model.CompetitorReport
+	for _, report := range reports {
+		if report.Category == category {
+			inCategory = append(inCategory, report)
+		}
+	}
+	return inCategory
+}
+
+// AtDisplayResolution returns copies of the reports with every time brought
+// to the display resolution of the config.
+func AtDisplayResolution(reports []model.CompetitorReport, config model.Config) []model.CompetitorReport {
+	if config.DisplayResolution <= 0 {
+		return reports
+	}
+	display := func(d time.Duration) time.Duration {
+		return config.Rounding.Duration(d, config.DisplayResolution)
+	}
+	displayLaps := func(laps []model.LapInfo) []model.LapInfo {
+		displayed := slices.Clone(laps)
+		for i := range displayed {
+			displayed[i].Time = display(displayed[i].Time)
+			displayed[i].Split = display(displayed[i].Split)
+		}
+		return displayed
+	}
+
+	displayed := make([]model.CompetitorReport, len(reports))
+	for i, report := range reports {
+		report.TotalTime = display(report.TotalTime)
+		report.GapToLeader = display(report.GapToLeader)
+		report.GapToAhead = display(report.GapToAhead)
+		report.StartLag = display(report.StartLag)
+		report.TimePenalty = display(report.TimePenalty)
+		report.PenaltyTime = display(report.PenaltyTime)
+		report.Laps = displayLaps(report.Laps)
+		report.PenaltyLaps = displayLaps(report.PenaltyLaps)
+
+		report.FiringRanges = slices.Clone(report.FiringRanges)
+		for j := range report.FiringRanges {
+			report.FiringRanges[j].Time = display(report.FiringRanges[j].Time)
+		}
+		displayed[i] = report
+	}
+	return displayed
+}
+
+// HitMask writes the targets of a visit as 1 for a hit and 0 for a miss.
+func HitMask(visit model.FiringRangeVisit) string {
+	mask := []byte(strings.Repeat("0", visit.Targets))
+	for _, target := range visit.HitTargets {
+		if target >= 1 && target <= visit.Targets {
+			mask[target-1] = '1'
+		}
+	}
+	return string(mask)
+}
+
+func writeJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func formatMs(ms int64) string {
+	return strconv.FormatInt(ms, 10)
+}
+
+func formatBib(bib int) string {
+	if bib == 0 {
+		return ""
+	}
+	return strconv.Itoa(bib)
+}
+
+func formatPlace(place int) string {
+	if place == 0 {
+		return ""
+	}
+	return strconv.Itoa(place)
+}
+
+func formatSpeed(speed float64) string {
+	return strconv.FormatFloat(speed, 'f', 3, 64)
+}
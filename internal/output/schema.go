@@ -0,0 +1,145 @@
+package output
+
+import "github.com/Maksim646/sunny_5_skiers/model"
+
+// SchemaVersion is the version of the JSON documents written by this package.
+// It changes whenever a field is renamed, removed or changes its meaning.
+// Version 2 adds the Disqualified and Lapped statuses and the reason field.
+const SchemaVersion = 2
+
+// Results is the JSON document of the result table.
+type Results struct {
+	SchemaVersion int          `json:"schemaVersion"`
+	Results       []Competitor `json:"results"`
+}
+
+// Log is the JSON document of the output log.
+type Log struct {
+	SchemaVersion int        `json:"schemaVersion"`
+	Events        []LogEntry `json:"events"`
+}
+
+// LogEntry is an incoming or outgoing event together with its log line.
+type LogEntry struct {
+	Time        string `json:"time"`
+	ID          int    `json:"id"`
+	Competitor  int    `json:"competitor"`
+	ExtraParams string `json:"extraParams,omitempty"`
+	Line        string `json:"line"`
+}
+
+type Lap struct {
+	TimeMs    int64   `json:"timeMs"`
+	Speed     float64 `json:"speed"`
+	SplitMs   int64   `json:"splitMs,omitempty"`
+	SplitRank int     `json:"splitRank,omitempty"`
+}
+
+type FiringRange struct {
+	FiringRange int    `json:"firingRange"`
+	Position    string `json:"position"`
+	Targets     int    `json:"targets"`
+	HitTargets  []int  `json:"hitTargets"`
+	Misses      int    `json:"misses"`
+	TimeMs      int64  `json:"timeMs"`
+}
+
+// Competitor is the JSON form of model.CompetitorReport. Durations are in
+// milliseconds, speeds in m/s. Roster fields are left out for competitors
+// that are not on the roster, Place is 0 for competitors without a result.
+type Competitor struct {
+	CompetitorID  int           `json:"competitorId"`
+	Bib           int           `json:"bib,omitempty"`
+	Name          string        `json:"name,omitempty"`
+	Nation        string        `json:"nation,omitempty"`
+	Club          string        `json:"club,omitempty"`
+	Category      string        `json:"category,omitempty"`
+	Place         int           `json:"place"`
+	CategoryPlace int           `json:"categoryPlace,omitempty"`
+	Gender        string        `json:"gender,omitempty"`
+	Status        string        `json:"status"`
+	Reason        string        `json:"reason,omitempty"`
+	TotalTimeMs   int64         `json:"totalTimeMs"`
+	GapToLeaderMs int64         `json:"gapToLeaderMs"`
+	GapToAheadMs  int64         `json:"gapToAheadMs"`
+	StartLagMs    int64         `json:"startLagMs"`
+	Laps          []Lap         `json:"laps"`
+	FiringRanges  []FiringRange `json:"firingRanges"`
+	Hits          int           `json:"hits"`
+	Shots         int           `json:"shots"`
+	TimePenaltyMs int64         `json:"timePenaltyMs"`
+
+	PenaltyLaps        []Lap   `json:"penaltyLaps"`
+	PenaltyLoops       int     `json:"penaltyLoops"`
+	PenaltyLoopsRun    int     `json:"penaltyLoopsRun"`
+	MissedPenaltyLoops int     `json:"missedPenaltyLoops"`
+	PenaltyTimeMs      int64   `json:"penaltyTimeMs"`
+	PenaltyDistance    int     `json:"penaltyDistance"`
+	PenaltySpeed       float64 `json:"penaltySpeed"`
+}
+
+func NewCompetitors(reports []model.CompetitorReport) []Competitor {
+	competitors := make([]Competitor, 0, len(reports))
+	for _, report := range reports {
+		competitors = append(competitors, NewCompetitor(report))
+	}
+	return competitors
+}
+
+func NewCompetitor(report model.CompetitorReport) Competitor {
+	firingRanges := make([]FiringRange, 0, len(report.FiringRanges))
+	for _, visit := range report.FiringRanges {
+		firingRanges = append(firingRanges, FiringRange{
+			FiringRange: visit.FiringRange,
+			Position:    visit.Position,
+			Targets:     visit.Targets,
+			HitTargets:  append([]int{}, visit.HitTargets...),
+			Misses:      visit.Misses,
+			TimeMs:      visit.Time.Milliseconds(),
+		})
+	}
+
+	return Competitor{
+		CompetitorID:  report.CompetitorID,
+		Bib:           report.Athlete.Bib,
+		Name:          report.Athlete.Name,
+		Nation:        report.Athlete.Nation,
+		Club:          report.Athlete.Club,
+		Category:      report.Category,
+		Place:         report.Place,
+		CategoryPlace: report.CategoryPlace,
+		Gender:        report.Athlete.Gender,
+		Status:        string(report.Status),
+		Reason:        report.Reason,
+		TotalTimeMs:   report.TotalTime.Milliseconds(),
+		GapToLeaderMs: report.GapToLeader.Milliseconds(),
+		GapToAheadMs:  report.GapToAhead.Milliseconds(),
+		StartLagMs:    report.StartLag.Milliseconds(),
+		Laps:          newLaps(report.Laps),
+		FiringRanges:  firingRanges,
+		Hits:          report.Hits,
+		Shots:         report.Shots,
+		TimePenaltyMs: report.TimePenalty.Milliseconds(),
+
+		PenaltyLaps:        newLaps(report.PenaltyLaps),
+		PenaltyLoops:       report.PenaltyLoops,
+		PenaltyLoopsRun:    report.PenaltyLoopsRun,
+		MissedPenaltyLoops: report.MissedPenaltyLoops,
+		PenaltyTimeMs:      report.PenaltyTime.Milliseconds(),
+		PenaltyDistance:    report.PenaltyDistance,
+		PenaltySpeed:       report.PenaltySpeed,
+	}
+}
+
+func newLaps(laps []model.LapInfo) []Lap {
+	lapsJSON := make([]Lap, 0, len(laps))
+	for _, lap := range laps {
+		lapsJSON = append(lapsJSON, Lap{
+			TimeMs:    lap.Time.Milliseconds(),
+			Speed:     lap.Speed,
+			SplitMs:   lap.Split.Milliseconds(),
+			SplitRank: lap.SplitRank,
+		})
+	}
+	return lapsJSON
+}
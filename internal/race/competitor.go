@@ -0,0 +1,58 @@
+package race
+
+import (
+	"time"
+
+	"github.com/Maksim646/sunny_5_skiers/model"
+)
+
+type competitor struct {
+	id int
+
+	scheduledStart time.Time
+	scheduled      bool
+
+	started      bool
+	disqualified bool
+	retired      bool
+	finished     bool
+	// juryDisqualified and lapped are decisions of the jury, reason holds
+	// the reason of the last decision, retirement or missed start.
+	juryDisqualified bool
+	lapped           bool
+	reason           string
+
+	startTime            time.Time
+	finishTime           time.Time
+	lapStartTime         time.Time
+	penaltyLapStartTime  time.Time
+	firingRangeStartTime time.Time
+
+	onFiringRange bool
+
+	laps        []model.LapInfo
+	penaltyLaps []model.LapInfo
+	visits      []model.FiringRangeVisit
+	hits        int
+
+	inPenalty          bool
+	owedPenaltyLoops   int
+	timePenalty        time.Duration
+	penaltyLoopsRun    int
+	missedPenaltyLoops int
+}
+
+// plannedStart is the scheduled start time, or the actual start for a
+// competitor who started without one. Total and first lap times are measured
+// from it.
+func (c *competitor) plannedStart() time.Time {
+	if c.scheduled {
+		return c.scheduledStart
+	}
+	return c.startTime
+}
+
+// inRace reports whether the competitor can still finish.
+func (c *competitor) inRace() bool {
+	return !c.disqualified && !c.juryDisqualified && !c.retired && !c.lapped && !c.finished
+}
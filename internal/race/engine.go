@@ -0,0 +1,391 @@
+package race
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Maksim646/sunny_5_skiers/model"
+	"go.uber.org/zap"
+)
+
+// Engine consumes competitor events one at a time and keeps the state of
+// every competitor, so the output log and the result table are derived from
+// the same data.
+type Engine struct {
+	config            model.Config
+	timeFormat        string
+	targetsInFireLine int
+	rules             Rules
+
+	competitors map[int]*competitor
+	lastTime    time.Time
+	seen        bool
+	finished    bool
+}
+
+func NewEngine(config model.Config, timeFormat string, targetsInFireLine int) *Engine {
+	return &Engine{
+		config:            config,
+		timeFormat:        timeFormat,
+		targetsInFireLine: targetsInFireLine,
+		rules:             NewRules(config),
+		competitors:       make(map[int]*competitor),
+	}
+}
+
+func (e *Engine) Config() model.Config {
+	return e.config
+}
+
+func (e *Engine) TimeFormat() string {
+	return e.timeFormat
+}
+
+// Apply processes a single incoming event and returns it together with the
+// outgoing events it causes, in the order they must appear in the output log.
+func (e *Engine) Apply(event model.CompetitorEvent) ([]model.CompetitorEvent, error) {
+	if event.Competitor == 0 {
+		zap.L().Info(fmt.Sprintf("warning: event without competitor ID: %+v", event))
+	}
+	event.Time = e.config.Rounding.Time(event.Time, e.config.InputResolution)
+
+	outputEvents := e.disqualifyLate(func(deadline time.Time) bool { return deadline.Before(event.Time) })
+
+	c := e.competitor(event.Competitor)
+
+	switch event.ID {
+	case model.EventRegistered:
+		e.schedule(c, event.Time, time.Time{})
+	case model.EventStartTimeSet:
+		startTime, err := time.Parse(e.timeFormat, event.ExtraParams)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start time for competitor(%d): %w", event.Competitor, err)
+		}
+		e.schedule(c, event.Time, startTime)
+	case model.EventStart:
+		c.started = true
+		c.startTime = event.Time
+		c.lapStartTime = c.plannedStart()
+	case model.EventOnTheFiringRange:
+		firingRange, _ := strconv.Atoi(event.ExtraParams)
+		c.visits = append(c.visits, model.FiringRangeVisit{
+			FiringRange: firingRange,
+			Position:    e.rules.Position(len(c.visits) + 1),
+			Targets:     e.targetsInFireLine,
+			Misses:      e.targetsInFireLine,
+		})
+		c.onFiringRange = true
+		c.firingRangeStartTime = event.Time
+	case model.EventTargetHit:
+		c.hits += 1
+		if c.onFiringRange {
+			visit := &c.visits[len(c.visits)-1]
+			target, _ := strconv.Atoi(event.ExtraParams)
+			if !slices.Contains(visit.HitTargets, target) {
+				visit.HitTargets = append(visit.HitTargets, target)
+				visit.Misses = max(visit.Targets-len(visit.HitTargets), 0)
+			}
+		}
+	case model.EventLeftFiringRange:
+		if c.onFiringRange {
+			visit := &c.visits[len(c.visits)-1]
+			visit.Time = event.Time.Sub(c.firingRangeStartTime)
+			c.owedPenaltyLoops += e.rules.PenaltyLoops(visit.Misses)
+			c.timePenalty += e.rules.TimePenalty(visit.Misses)
+			c.onFiringRange = false
+		}
+	case model.EventPenaltyLapStart:
+		if !e.rules.HasPenaltyLoops() {
+			zap.L().Warn("penalty laps ignored in a format with a time penalty", zap.Int("competitor", c.id))
+			break
+		}
+		c.penaltyLapStartTime = event.Time
+		c.inPenalty = true
+	case model.EventPenaltyLapEnd:
+		if !c.inPenalty {
+			break
+		}
+		penaltyLapDuration := event.Time.Sub(c.penaltyLapStartTime)
+		loops := e.penaltyLoopsRun(c.owedPenaltyLoops, penaltyLapDuration)
+		c.penaltyLaps = append(c.penaltyLaps, model.LapInfo{
+			Time:  penaltyLapDuration,
+			Speed: speed(loops*e.config.PenaltyLen, penaltyLapDuration),
+		})
+		c.penaltyLoopsRun += loops
+		if missed := c.owedPenaltyLoops - loops; missed > 0 {
+			zap.L().Warn("competitor skipped penalty loops",
+				zap.Int("competitor", c.id), zap.Int("loops", missed))
+			c.missedPenaltyLoops += missed
+		}
+		c.owedPenaltyLoops = 0
+		c.inPenalty = false
+	case model.EventLapCompleted:
+		if c.owedPenaltyLoops > 0 {
+			zap.L().Warn("competitor skipped penalty loops",
+				zap.Int("competitor", c.id), zap.Int("loops", c.owedPenaltyLoops))
+			c.missedPenaltyLoops += c.owedPenaltyLoops
+			c.owedPenaltyLoops = 0
+		}
+		lapDuration := event.Time.Sub(c.lapStartTime)
+		c.laps = append(c.laps, model.LapInfo{
+			Time:  lapDuration,
+			Speed: speed(e.config.LapLen, lapDuration),
+		})
+		c.lapStartTime = event.Time
+	case model.EventNotFinished:
+		c.retired = true
+		c.reason = event.ExtraParams
+	case model.EventJuryDisqualified:
+		c.juryDisqualified = true
+		c.reason = event.ExtraParams
+	case model.EventLapped:
+		c.lapped = true
+	}
+
+	outputEvents = append(outputEvents, event)
+	if !e.seen || event.Time.After(e.lastTime) {
+		e.lastTime = event.Time
+		e.seen = true
+	}
+
+	if event.ID == model.EventLapCompleted && len(c.laps) == e.config.Laps && c.inRace() {
+		c.finished = true
+		c.finishTime = event.Time
+		outputEvents = append(outputEvents, model.CompetitorEvent{
+			Time:       event.Time,
+			ID:         model.EventFinished,
+			Competitor: event.Competitor,
+		})
+	}
+
+	return outputEvents, nil
+}
+
+// Finish closes the race: every competitor who has a scheduled start time but
+// never started is disqualified at the end of their start interval, and
+// everyone still on the course is reported as not finished.
+func (e *Engine) Finish() []model.CompetitorEvent {
+	e.finished = true
+	return e.disqualifyLate(func(time.Time) bool { return true })
+}
+
+// Report returns the current report of a single competitor.
+func (e *Engine) Report(competitorID int) (model.CompetitorReport, bool) {
+	if _, ok := e.competitors[competitorID]; !ok {
+		return model.CompetitorReport{}, false
+	}
+
+	for _, report := range e.Reports() {
+		if report.CompetitorID == competitorID {
+			return report, true
+		}
+	}
+	return model.CompetitorReport{}, false
+}
+
+// Reports returns the current report of every competitor sorted by standings.
+func (e *Engine) Reports() []model.CompetitorReport {
+	competitorIDs := make([]int, 0, len(e.competitors))
+	for competitorID := range e.competitors {
+		competitorIDs = append(competitorIDs, competitorID)
+	}
+	sort.Ints(competitorIDs)
+
+	reports := make([]model.CompetitorReport, 0, len(competitorIDs))
+	for _, competitorID := range competitorIDs {
+		reports = append(reports, e.report(e.competitors[competitorID]))
+	}
+
+	rank(reports, rankingResolution{e.config.RankingResolution, e.config.Rounding})
+
+	return reports
+}
+
+// category returns the roster category of a competitor, or the one of the config.
+func (e *Engine) category(competitorID int) string {
+	if athlete, ok := e.config.Roster[competitorID]; ok && athlete.Category != "" {
+		return athlete.Category
+	}
+	for category, competitorIDs := range e.config.Categories {
+		if slices.Contains(competitorIDs, competitorID) {
+			return category
+		}
+	}
+	return ""
+}
+
+func (e *Engine) report(c *competitor) model.CompetitorReport {
+	status := model.CompetitorStarted
+	switch {
+	case c.juryDisqualified:
+		status = model.CompetitorDisqualified
+	case !c.started || c.disqualified:
+		status = model.CompetitorNotStarted
+	case c.retired:
+		status = model.CompetitorNotFinished
+	case c.lapped:
+		status = model.CompetitorLapped
+	case !c.finished && e.finished:
+		status = model.CompetitorNotFinished
+	case !c.finished:
+		status = model.CompetitorRunning
+	}
+
+	totalTime := time.Duration(0)
+	if status == model.CompetitorStarted && c.finished {
+		totalTime = c.finishTime.Sub(c.plannedStart()) + c.timePenalty
+	}
+
+	startLag := time.Duration(0)
+	if c.started && c.scheduled {
+		startLag = c.startTime.Sub(c.scheduledStart)
+	}
+
+	visits := make([]model.FiringRangeVisit, len(c.visits))
+	shots := 0
+	penaltyLoops := 0
+	for i, visit := range c.visits {
+		visit.HitTargets = append([]int(nil), visit.HitTargets...)
+		visits[i] = visit
+		shots += visit.Targets
+		penaltyLoops += e.rules.PenaltyLoops(visit.Misses)
+	}
+
+	penaltyTime := time.Duration(0)
+	for _, penaltyLap := range c.penaltyLaps {
+		penaltyTime += penaltyLap.Time
+	}
+	penaltyDistance := c.penaltyLoopsRun * e.config.PenaltyLen
+
+	return model.CompetitorReport{
+		CompetitorID: c.id,
+		Athlete:      e.config.Roster[c.id],
+		Category:     e.category(c.id),
+		Status:       status,
+		Reason:       c.reason,
+		TotalTime:    totalTime,
+		StartLag:     startLag,
+		Laps:         splits(c.laps),
+		FiringRanges: visits,
+		Hits:         c.hits,
+		Shots:        shots,
+		TimePenalty:  c.timePenalty,
+
+		PenaltyLaps:        append([]model.LapInfo(nil), c.penaltyLaps...),
+		PenaltyLoops:       penaltyLoops,
+		PenaltyLoopsRun:    c.penaltyLoopsRun,
+		MissedPenaltyLoops: c.missedPenaltyLoops,
+		PenaltyTime:        penaltyTime,
+		PenaltyDistance:    penaltyDistance,
+		PenaltySpeed:       speed(penaltyDistance, penaltyTime),
+	}
+}
+
+func (e *Engine) disqualifyLate(expired func(deadline time.Time) bool) []model.CompetitorEvent {
+	var disqualified []model.CompetitorEvent
+
+	for _, c := range e.competitors {
+		if !c.scheduled || c.started || c.disqualified {
+			continue
+		}
+
+		deadline := c.scheduledStart.Add(e.rules.StartWindow())
+		if !expired(deadline) {
+			continue
+		}
+
+		c.disqualified = true
+		c.reason = "missed the start interval"
+		if e.seen && deadline.Before(e.lastTime) {
+			deadline = e.lastTime
+		}
+		disqualified = append(disqualified, model.CompetitorEvent{
+			Time:       deadline,
+			ID:         model.EventDisqualified,
+			Competitor: c.id,
+		})
+	}
+
+	sort.Slice(disqualified, func(i, j int) bool {
+		if disqualified[i].Time.Equal(disqualified[j].Time) {
+			return disqualified[i].Competitor < disqualified[j].Competitor
+		}
+		return disqualified[i].Time.Before(disqualified[j].Time)
+	})
+
+	if len(disqualified) > 0 {
+		e.lastTime = disqualified[len(disqualified)-1].Time
+	}
+
+	return disqualified
+}
+
+// penaltyLoopsRun counts the loops of a stay in the penalty laps. There is no
+// event per loop, so a stay covers every loop owed since the last stay, and
+// at least one, but no more than the stay allows at MaxPenaltySpeed.
+func (e *Engine) penaltyLoopsRun(owed int, stay time.Duration) int {
+	loops := max(owed, 1)
+	if e.config.MaxPenaltySpeed <= 0 || e.config.PenaltyLen <= 0 {
+		return loops
+	}
+
+	// The epsilon keeps a stay of exactly n loops at the limit from rounding down.
+	possible := int(math.Floor(stay.Seconds()*e.config.MaxPenaltySpeed/float64(e.config.PenaltyLen) + 1e-9))
+	return min(loops, possible)
+}
+
+// schedule sets the planned start of the competitor from the format rules.
+// drawn is zero for the registration event.
+func (e *Engine) schedule(c *competitor, at time.Time, drawn time.Time) {
+	if start, ok := e.rules.ScheduledStart(drawn); ok {
+		c.scheduledStart = clockOn(at, start)
+		if at.Sub(c.scheduledStart) > 12*time.Hour {
+			// A start drawn before midnight for the next day.
+			c.scheduledStart = c.scheduledStart.AddDate(0, 0, 1)
+		}
+		c.scheduled = true
+	}
+}
+
+func (e *Engine) competitor(competitorID int) *competitor {
+	c, ok := e.competitors[competitorID]
+	if !ok {
+		c = &competitor{id: competitorID}
+		e.competitors[competitorID] = c
+	}
+	return c
+}
+
+// splits copies the laps with their cumulative times.
+func splits(laps []model.LapInfo) []model.LapInfo {
+	withSplits := make([]model.LapInfo, len(laps))
+	var split time.Duration
+	for i, lap := range laps {
+		split += lap.Time
+		lap.Split = split
+		withSplits[i] = lap
+	}
+	return withSplits
+}
+
+func hasResult(report model.CompetitorReport) bool {
+	return report.Status == model.CompetitorStarted
+}
+
+func speed(distance int, d time.Duration) float64 {
+	seconds := d.Seconds()
+	if seconds <= 0 {
+		return 0
+	}
+	return float64(distance) / seconds
+}
+
+// clockOn places the wall clock time of clock on the day of day.
+func clockOn(day time.Time, clock time.Time) time.Time {
+	year, month, date := day.Date()
+	return time.Date(year, month, date, clock.Hour(), clock.Minute(), clock.Second(), clock.Nanosecond(), day.Location())
+}
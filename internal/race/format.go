@@ -0,0 +1,46 @@
+package race
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/Maksim646/sunny_5_skiers/model"
+)
+
+// LogLine formats an incoming or outgoing event as a line of the output log.
+func (e *Engine) LogLine(event model.CompetitorEvent) string {
+	return formatEventWithComment(event, model.Comments, e.timeFormat, e.config.Roster)
+}
+
+func formatEventWithComment(event model.CompetitorEvent, comments map[int]string, timeFormat string, roster model.Roster) string {
+	timeStr := event.Time.Format(timeFormat)
+	competitor := competitorLabel(event.Competitor, roster)
+	var msg string
+
+	switch event.ID {
+	case model.EventRegistered, model.EventOnTheStartLine, model.EventStart, model.EventLeftFiringRange, model.EventPenaltyLapStart, model.EventPenaltyLapEnd, model.EventLapCompleted,
+		model.EventLapped, model.EventDisqualified, model.EventFinished:
+		msg = fmt.Sprintf("The competitor(%s) %s", competitor, comments[event.ID])
+	case model.EventStartTimeSet:
+		msg = fmt.Sprintf("The start time for the competitor(%s) was set by a draw to %s", competitor, event.ExtraParams)
+	case model.EventOnTheFiringRange:
+		msg = fmt.Sprintf("The competitor(%s) %s(%s)", competitor, comments[event.ID], event.ExtraParams)
+	case model.EventTargetHit:
+		msg = fmt.Sprintf("The target(%s) has been hit by competitor(%s)", event.ExtraParams, competitor)
+	case model.EventNotFinished, model.EventJuryDisqualified:
+		msg = fmt.Sprintf("The competitor(%s) %s: %s", competitor, comments[event.ID], event.ExtraParams)
+	default:
+		msg = fmt.Sprintf("Unknown event ID (%d) for competitor(%s)", event.ID, competitor)
+	}
+
+	return fmt.Sprintf("[%s] %s", timeStr, msg)
+}
+
+// competitorLabel writes the competitor ID followed by the name from the roster, if any.
+func competitorLabel(competitorID int, roster model.Roster) string {
+	athlete, ok := roster[competitorID]
+	if !ok || athlete.Name == "" {
+		return strconv.Itoa(competitorID)
+	}
+	return fmt.Sprintf("%d, %s", competitorID, athlete.Name)
+}
@@ -0,0 +1,180 @@
+package race
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Maksim646/sunny_5_skiers/model"
+)
+
+// standingsClasses orders the groups of the result table: finishers, then
+// competitors still on the course, then LAP, DNF, DNS and DSQ.
+var standingsClasses = map[model.Status]int{
+	model.CompetitorStarted:      0,
+	model.CompetitorRunning:      1,
+	model.CompetitorLapped:       2,
+	model.CompetitorNotFinished:  3,
+	model.CompetitorNotStarted:   4,
+	model.CompetitorDisqualified: 5,
+}
+
+// rank sorts reports by standings and assigns places, gaps and split ranks.
+// Times are compared at resolution, equal times share a place. reports must
+// be sorted by competitor ID, so the order never depends on the input order.
+func rank(reports []model.CompetitorReport, resolution rankingResolution) {
+	sort.SliceStable(reports, func(i, j int) bool {
+		return standingsLess(reports[i], reports[j], resolution)
+	})
+
+	assignPlaces(reports, resolution)
+	assignGaps(reports, resolution)
+	assignSplitRanks(reports, resolution)
+}
+
+// standingsLess orders finishers by time, competitors on the course, LAP and
+// DNF by laps completed and then by their last split, and uses the bib as the
+// final key.
+func standingsLess(a, b model.CompetitorReport, resolution rankingResolution) bool {
+	if classA, classB := standingsClass(a), standingsClass(b); classA != classB {
+		return classA < classB
+	}
+
+	switch a.Status {
+	case model.CompetitorStarted:
+		if timeA, timeB := resolution.at(a.TotalTime), resolution.at(b.TotalTime); timeA != timeB {
+			return timeA < timeB
+		}
+	case model.CompetitorRunning, model.CompetitorLapped, model.CompetitorNotFinished:
+		if len(a.Laps) != len(b.Laps) {
+			return len(a.Laps) > len(b.Laps)
+		}
+		if splitA, splitB := resolution.at(lastSplit(a)), resolution.at(lastSplit(b)); splitA != splitB {
+			return splitA < splitB
+		}
+	}
+
+	return bibLess(a, b)
+}
+
+func standingsClass(report model.CompetitorReport) int {
+	if class, ok := standingsClasses[report.Status]; ok {
+		return class
+	}
+	return len(standingsClasses)
+}
+
+func lastSplit(report model.CompetitorReport) time.Duration {
+	if len(report.Laps) == 0 {
+		return 0
+	}
+	return report.Laps[len(report.Laps)-1].Split
+}
+
+// bibLess orders competitors by bib, competitors without a bib come last
+// ordered by competitor ID.
+func bibLess(a, b model.CompetitorReport) bool {
+	hasBibA, hasBibB := a.Athlete.Bib > 0, b.Athlete.Bib > 0
+	if hasBibA != hasBibB {
+		return hasBibA
+	}
+	if a.Athlete.Bib != b.Athlete.Bib {
+		return a.Athlete.Bib < b.Athlete.Bib
+	}
+	return a.CompetitorID < b.CompetitorID
+}
+
+// rankingResolution is the precision times are compared at and its rounding rule.
+type rankingResolution struct {
+	step     time.Duration
+	rounding model.Rounding
+}
+
+// at returns d at the resolution, a step of 0 keeps d as is.
+func (r rankingResolution) at(d time.Duration) time.Duration {
+	return r.rounding.Duration(d, r.step)
+}
+
+// assignPlaces numbers the competitors with a result overall and within
+// their category, reports must be sorted by standings. Competitors with
+// equal times share a place and the next place is skipped, e.g. 1, 2, 2, 4.
+func assignPlaces(reports []model.CompetitorReport, resolution rankingResolution) {
+	type counter struct {
+		count    int
+		place    int
+		lastTime time.Duration
+	}
+
+	next := func(c *counter, totalTime time.Duration) int {
+		c.count++
+		if c.count == 1 || totalTime != c.lastTime {
+			c.place = c.count
+		}
+		c.lastTime = totalTime
+		return c.place
+	}
+
+	var overall counter
+	categories := make(map[string]*counter)
+	for i := range reports {
+		if !hasResult(reports[i]) {
+			continue
+		}
+
+		totalTime := resolution.at(reports[i].TotalTime)
+		reports[i].Place = next(&overall, totalTime)
+
+		if category := reports[i].Category; category != "" {
+			if categories[category] == nil {
+				categories[category] = &counter{}
+			}
+			reports[i].CategoryPlace = next(categories[category], totalTime)
+		}
+	}
+}
+
+// assignGaps measures the gaps of the competitors with a result at the
+// resolution, reports must be sorted by standings.
+func assignGaps(reports []model.CompetitorReport, resolution rankingResolution) {
+	var leader, ahead time.Duration
+	seen := false
+	for i := range reports {
+		if !hasResult(reports[i]) {
+			continue
+		}
+
+		totalTime := resolution.at(reports[i].TotalTime)
+		if !seen {
+			leader, ahead, seen = totalTime, totalTime, true
+		}
+		reports[i].GapToLeader = totalTime - leader
+		reports[i].GapToAhead = totalTime - ahead
+		ahead = totalTime
+	}
+}
+
+// assignSplitRanks ranks every lap split among the splits of the same lap,
+// splits equal at the resolution share a rank.
+func assignSplitRanks(reports []model.CompetitorReport, resolution rankingResolution) {
+	var splits [][]time.Duration
+	for _, report := range reports {
+		for lap, info := range report.Laps {
+			if lap == len(splits) {
+				splits = append(splits, nil)
+			}
+			splits[lap] = append(splits[lap], resolution.at(info.Split))
+		}
+	}
+
+	for i := range reports {
+		for lap := range reports[i].Laps {
+			own := resolution.at(reports[i].Laps[lap].Split)
+			rank := 1
+			for _, split := range splits[lap] {
+				if split < own {
+					rank++
+				}
+			}
+			reports[i].Laps[lap].SplitRank = rank
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package race
+
+import (
+	"time"
+
+	"github.com/Maksim646/sunny_5_skiers/model"
+)
+
+const defaultPenaltyTime = time.Minute
+
+// Rules holds everything that differs between competition formats.
+type Rules interface {
+	// ScheduledStart returns the planned start of a competitor. drawn is the
+	// start time set by event 2 and is zero until the draw.
+	ScheduledStart(drawn time.Time) (time.Time, bool)
+	// StartWindow is how long after the planned start a competitor may still start.
+	StartWindow() time.Duration
+	// PenaltyLoops returns the penalty loops owed for the misses of one firing range visit.
+	PenaltyLoops(misses int) int
+	// TimePenalty returns the time added for the misses of one firing range visit.
+	TimePenalty(misses int) time.Duration
+	// HasPenaltyLoops reports whether misses are run off in penalty loops,
+	// formats with a time penalty have no penalty laps.
+	HasPenaltyLoops() bool
+	// Position returns the shooting position of the n-th firing range visit, counting from 1.
+	Position(visit int) string
+}
+
+// NewRules returns the rules of the configured format, falling back to sprint.
+func NewRules(config model.Config) Rules {
+	switch config.Format {
+	case model.FormatIndividual:
+		penaltyTime := config.PenaltyTime
+		if penaltyTime == 0 {
+			penaltyTime = defaultPenaltyTime
+		}
+		return individualRules{intervalStart: intervalStart{config.StartDelta}, penaltyTime: penaltyTime}
+	case model.FormatPursuit:
+		return pursuitRules{intervalStart: intervalStart{config.StartDelta}}
+	case model.FormatMassStart:
+		return massStartRules{start: config.Start, window: config.StartDelta}
+	default:
+		return sprintRules{intervalStart: intervalStart{config.StartDelta}}
+	}
+}
+
+var (
+	proneStanding      = []string{model.PositionProne, model.PositionStanding}
+	proneProneStanding = []string{model.PositionProne, model.PositionProne, model.PositionStanding, model.PositionStanding}
+)
+
+func positionAt(order []string, visit int) string {
+	if visit < 1 {
+		return ""
+	}
+	return order[(visit-1)%len(order)]
+}
+
+// intervalStart lets every competitor start at their drawn time.
+type intervalStart struct {
+	window time.Duration
+}
+
+func (s intervalStart) ScheduledStart(drawn time.Time) (time.Time, bool) {
+	return drawn, !drawn.IsZero()
+}
+
+func (s intervalStart) StartWindow() time.Duration {
+	return s.window
+}
+
+// loopPenalty sends the competitor to one penalty loop for every miss.
+type loopPenalty struct{}
+
+func (loopPenalty) PenaltyLoops(misses int) int {
+	return misses
+}
+
+func (loopPenalty) TimePenalty(int) time.Duration {
+	return 0
+}
+
+func (loopPenalty) HasPenaltyLoops() bool {
+	return true
+}
+
+type sprintRules struct {
+	intervalStart
+	loopPenalty
+}
+
+func (sprintRules) Position(visit int) string {
+	return positionAt(proneStanding, visit)
+}
+
+// individualRules adds a fixed time for every miss instead of a penalty loop.
+type individualRules struct {
+	intervalStart
+	penaltyTime time.Duration
+}
+
+func (individualRules) PenaltyLoops(int) int {
+	return 0
+}
+
+func (r individualRules) TimePenalty(misses int) time.Duration {
+	return time.Duration(misses) * r.penaltyTime
+}
+
+func (individualRules) HasPenaltyLoops() bool {
+	return false
+}
+
+func (individualRules) Position(visit int) string {
+	return positionAt(proneStanding, visit)
+}
+
+// pursuitRules start competitors at the gaps of a previous race, which come
+// in as drawn start times.
+type pursuitRules struct {
+	intervalStart
+	loopPenalty
+}
+
+func (pursuitRules) Position(visit int) string {
+	return positionAt(proneProneStanding, visit)
+}
+
+// massStartRules start every competitor together at Config.Start.
+type massStartRules struct {
+	loopPenalty
+	start  time.Time
+	window time.Duration
+}
+
+func (r massStartRules) ScheduledStart(time.Time) (time.Time, bool) {
+	return r.start, true
+}
+
+func (r massStartRules) StartWindow() time.Duration {
+	return r.window
+}
+
+func (massStartRules) Position(visit int) string {
+	return positionAt(proneProneStanding, visit)
+}
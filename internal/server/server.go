@@ -0,0 +1,237 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/Maksim646/sunny_5_skiers/internal/controller"
+	"github.com/Maksim646/sunny_5_skiers/internal/output"
+	"github.com/Maksim646/sunny_5_skiers/internal/race"
+	"github.com/Maksim646/sunny_5_skiers/model"
+)
+
+// eventJSON is the JSON form of model.CompetitorEvent, the time is written
+// in the event time format.
+type eventJSON struct {
+	Time        string `json:"time"`
+	ID          int    `json:"id"`
+	Competitor  int    `json:"competitor"`
+	ExtraParams string `json:"extraParams,omitempty"`
+}
+
+// Server keeps processing events of a race and serves its state over HTTP.
+type Server struct {
+	config     model.Config
+	timeFormat string
+
+	mu        sync.Mutex
+	engine    *race.Engine
+	validator *controller.SequenceValidator
+	timeline  *controller.Timeline
+	log       []string
+	updates   []update
+	changed   chan struct{}
+
+	// done is closed by Close to end the streams.
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func New(config model.Config, timeFormat string, targetsInFireLine int, strict bool) *Server {
+	return &Server{
+		config:     config,
+		timeFormat: timeFormat,
+		engine:     race.NewEngine(config, timeFormat, targetsInFireLine),
+		validator:  controller.NewSequenceValidator(config, timeFormat, targetsInFireLine, strict),
+		timeline:   controller.NewTimeline(config.Date),
+		changed:    make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+}
+
+// Close ends every open stream, so that http.Server.Shutdown does not wait
+// for the clients to disconnect. Register it with RegisterOnShutdown.
+func (s *Server) Close() {
+	s.closeOnce.Do(func() { close(s.done) })
+}
+
+// Apply validates and processes a single event and returns the log lines it produced.
+func (s *Server) Apply(event model.CompetitorEvent) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The timeline and the validator only move on once the event is
+	// accepted, a rejected event leaves the race as it was.
+	timeline := *s.timeline
+	event = timeline.Place(event)
+	if err := s.validator.Validate(event); err != nil {
+		return nil, err
+	}
+
+	outputEvents, err := s.engine.Apply(event)
+	if err != nil {
+		return nil, err
+	}
+	*s.timeline = timeline
+	s.validator.Record(event)
+
+	lines := make([]string, 0, len(outputEvents))
+	for _, outputEvent := range outputEvents {
+		lines = append(lines, s.engine.LogLine(outputEvent))
+	}
+	s.log = append(s.log, lines...)
+	s.publish(outputEvents)
+
+	return lines, nil
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /competitors", s.handleCompetitors)
+	mux.HandleFunc("GET /competitors/{id}", s.handleCompetitor)
+	mux.HandleFunc("GET /results", s.handleResults)
+	mux.HandleFunc("GET /log", s.handleLog)
+	mux.HandleFunc("GET /config", s.handleConfig)
+	mux.HandleFunc("POST /events", s.handlePostEvents)
+	mux.HandleFunc("GET /stream", s.handleStream)
+	return mux
+}
+
+func (s *Server) handleCompetitors(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	reports := output.AtDisplayResolution(s.engine.Reports(), s.config)
+	s.mu.Unlock()
+
+	sort.Slice(reports, func(i, j int) bool { return reports[i].CompetitorID < reports[j].CompetitorID })
+	writeJSON(w, http.StatusOK, output.NewCompetitors(reports))
+}
+
+func (s *Server) handleCompetitor(w http.ResponseWriter, r *http.Request) {
+	competitorID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid competitor ID %q", r.PathValue("id")))
+		return
+	}
+
+	s.mu.Lock()
+	report, ok := s.engine.Report(competitorID)
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("competitor(%d) not found", competitorID))
+		return
+	}
+	writeJSON(w, http.StatusOK, s.competitor(report))
+}
+
+// competitor converts a report at the display resolution.
+func (s *Server) competitor(report model.CompetitorReport) output.Competitor {
+	return output.NewCompetitor(output.AtDisplayResolution([]model.CompetitorReport{report}, s.config)[0])
+}
+
+func (s *Server) handleResults(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	reports := output.AtDisplayResolution(s.engine.Reports(), s.config)
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, output.Results{SchemaVersion: output.SchemaVersion, Results: output.NewCompetitors(reports)})
+}
+
+func (s *Server) handleLog(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	log := append([]string{}, s.log...)
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, log)
+}
+
+func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.config)
+}
+
+// handlePostEvents accepts events either as lines of the input format or,
+// with a JSON content type, as a single event object or an array of them.
+// Events are applied in order until the first invalid one, the error reports
+// how many were applied before it.
+func (s *Server) handlePostEvents(w http.ResponseWriter, r *http.Request) {
+	events, err := s.readEvents(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	lines := []string{}
+	for applied, event := range events {
+		produced, err := s.Apply(event)
+		if err != nil {
+			status := http.StatusBadRequest
+			var sequenceErr *controller.SequenceError
+			if errors.As(err, &sequenceErr) {
+				status = http.StatusUnprocessableEntity
+			}
+			writeJSON(w, status, map[string]any{"error": err.Error(), "applied": applied})
+			return
+		}
+		lines = append(lines, produced...)
+	}
+
+	writeJSON(w, http.StatusOK, lines)
+}
+
+func (s *Server) readEvents(r *http.Request) ([]model.CompetitorEvent, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/json" {
+		return controller.ReadEvents(r.Body, "request", s.timeFormat)
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var eventsJSON []eventJSON
+	if err := json.Unmarshal(body, &eventsJSON); err != nil {
+		var single eventJSON
+		if err := json.Unmarshal(body, &single); err != nil {
+			return nil, fmt.Errorf("invalid JSON event: %w", err)
+		}
+		eventsJSON = []eventJSON{single}
+	}
+
+	events := make([]model.CompetitorEvent, 0, len(eventsJSON))
+	for i, e := range eventsJSON {
+		eventTime, err := controller.ParseEventTime(e.Time, s.timeFormat)
+		if err != nil {
+			return nil, fmt.Errorf("event %d: invalid time %q", i+1, e.Time)
+		}
+		event := model.CompetitorEvent{
+			Time:        eventTime,
+			ID:          e.ID,
+			Competitor:  e.Competitor,
+			ExtraParams: e.ExtraParams,
+		}
+		if err := controller.ValidateEvent(event, s.timeFormat); err != nil {
+			return nil, fmt.Errorf("event %d: %w", i+1, err)
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Maksim646/sunny_5_skiers/internal/output"
+	"github.com/Maksim646/sunny_5_skiers/model"
+)
+
+// updateTypes lists the events that change the standings, by the type of
+// the message they are streamed as.
+var updateTypes = map[int]string{
+	model.EventStart:            "started",
+	model.EventTargetHit:        "targetHit",
+	model.EventLeftFiringRange:  "shooting",
+	model.EventPenaltyLapEnd:    "penalty",
+	model.EventLapCompleted:     "lap",
+	model.EventNotFinished:      "notFinished",
+	model.EventJuryDisqualified: "juryDisqualified",
+	model.EventLapped:           "lapped",
+	model.EventDisqualified:     "disqualified",
+	model.EventFinished:         "finished",
+}
+
+// update is a single message of the live feed.
+type update struct {
+	Seq        int               `json:"seq"`
+	Type       string            `json:"type"`
+	EventID    int               `json:"eventId"`
+	Line       string            `json:"line"`
+	Competitor output.Competitor `json:"competitor"`
+}
+
+// publish records the updates caused by the output events and wakes up the
+// streams. It must be called with s.mu held.
+func (s *Server) publish(outputEvents []model.CompetitorEvent) {
+	published := false
+	for _, event := range outputEvents {
+		updateType, ok := updateTypes[event.ID]
+		if !ok {
+			continue
+		}
+		report, _ := s.engine.Report(event.Competitor)
+
+		s.updates = append(s.updates, update{
+			Seq:        len(s.updates) + 1,
+			Type:       updateType,
+			EventID:    event.ID,
+			Line:       s.engine.LogLine(event),
+			Competitor: s.competitor(report),
+		})
+		published = true
+	}
+
+	if published {
+		close(s.changed)
+		s.changed = make(chan struct{})
+	}
+}
+
+// handleStream sends the updates as Server-Sent Events. A client resumes
+// after the last message it received with the Last-Event-ID header or the
+// since query parameter. The stream ends when the client disconnects or the
+// server is closed.
+func (s *Server) handleStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("streaming is not supported"))
+		return
+	}
+
+	lastSeq := 0
+	since := r.Header.Get("Last-Event-ID")
+	if since == "" {
+		since = r.URL.Query().Get("since")
+	}
+	if since != "" {
+		seq, err := strconv.Atoi(since)
+		if err != nil || seq < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid sequence number %q", since))
+			return
+		}
+		lastSeq = seq
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		s.mu.Lock()
+		var pending []update
+		if lastSeq < len(s.updates) {
+			pending = append(pending, s.updates[lastSeq:]...)
+		}
+		changed := s.changed
+		s.mu.Unlock()
+
+		for _, u := range pending {
+			data, err := json.Marshal(u)
+			if err != nil {
+				return
+			}
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", u.Seq, u.Type, data); err != nil {
+				return
+			}
+			lastSeq = u.Seq
+		}
+		flusher.Flush()
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.done:
+			return
+		case <-changed:
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package _test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Maksim646/sunny_5_skiers/internal/controller"
+	"github.com/Maksim646/sunny_5_skiers/internal/output"
+	"github.com/Maksim646/sunny_5_skiers/internal/race"
+	"github.com/Maksim646/sunny_5_skiers/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCategories(t *testing.T) {
+	baseTime := time.Date(2025, time.May, 6, 10, 0, 0, 0, time.UTC)
+	config := model.Config{
+		Laps:        1,
+		LapLen:      3000,
+		PenaltyLen:  150,
+		FiringLines: 0,
+		Start:       baseTime,
+		StartDelta:  1 * time.Minute,
+		Roster: model.Roster{
+			1: {ID: 1, Name: "Ivan Petrov", Category: "Men"},
+			2: {ID: 2, Name: "Anna Berg", Category: "Women"},
+		},
+		Categories: map[string][]int{
+			"Men":   {3},
+			"Women": {4},
+		},
+	}
+
+	at := func(seconds int) time.Time {
+		return baseTime.Add(time.Duration(seconds) * time.Second)
+	}
+
+	var events []model.CompetitorEvent
+	finishes := map[int]int{1: 330, 2: 300, 3: 320, 4: 0}
+	for id := 1; id <= 4; id++ {
+		events = append(events,
+			model.CompetitorEvent{ID: 1, Competitor: id, Time: at(0)},
+			model.CompetitorEvent{ID: 2, Competitor: id, Time: at(1), ExtraParams: "10:00:30.000"},
+		)
+	}
+	for id := 1; id <= 4; id++ {
+		events = append(events, model.CompetitorEvent{ID: 4, Competitor: id, Time: at(30)})
+	}
+	for _, id := range []int{2, 3, 1} {
+		events = append(events, model.CompetitorEvent{ID: 10, Competitor: id, Time: at(finishes[id])})
+	}
+
+	engine := race.NewEngine(config, "15:04:05.000", 5)
+	applyEvents(t, engine, events)
+	engine.Finish()
+
+	t.Run("Places", func(t *testing.T) {
+		type place struct {
+			id            int
+			category      string
+			place         int
+			categoryPlace int
+		}
+
+		var actual []place
+		for _, report := range engine.Reports() {
+			actual = append(actual, place{report.CompetitorID, report.Category, report.Place, report.CategoryPlace})
+		}
+
+		assert.Equal(t, []place{
+			{2, "Women", 1, 1},
+			{3, "Men", 2, 1},
+			{1, "Men", 3, 2},
+			{4, "Women", 0, 0},
+		}, actual)
+
+		report, ok := engine.Report(1)
+		require.True(t, ok)
+		assert.Equal(t, 3, report.Place)
+		assert.Equal(t, 2, report.CategoryPlace)
+	})
+
+	t.Run("ResultTable", func(t *testing.T) {
+		actualPath := "test_process_events/test_result_table_categories_actual.txt"
+		expectedPath := "test_process_events/test_result_table_categories_expected.txt"
+
+		defer os.Remove(actualPath)
+
+		err := controller.GenerateResultingTable(engine, actualPath, output.FormatText, "hh:mm:ss.fff")
+		require.NoError(t, err, "GenerateResultingTable returned error")
+
+		actualContent, err := os.ReadFile(actualPath)
+		require.NoError(t, err, "Cannot read actual result file")
+
+		expectedContent, err := os.ReadFile(expectedPath)
+		require.NoError(t, err, "Cannot read expected result file")
+
+		assert.Equal(t, string(expectedContent), string(actualContent), "Generated result table does not match expected output")
+	})
+}
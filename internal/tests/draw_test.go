@@ -0,0 +1,113 @@
+package _test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Maksim646/sunny_5_skiers/internal/controller"
+	"github.com/Maksim646/sunny_5_skiers/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDraw(t *testing.T) {
+	timeFormat := "15:04:05.000"
+
+	parse := func(ts string) time.Time {
+		tm, err := time.Parse(timeFormat, ts)
+		require.NoError(t, err)
+		return tm
+	}
+
+	config := model.Config{
+		Start:      parse("10:00:00.000"),
+		StartDelta: 30 * time.Second,
+	}
+
+	var registrations []model.CompetitorEvent
+	for i, competitorID := range []int{5, 3, 8, 1, 7, 2, 6, 4} {
+		registrations = append(registrations, model.CompetitorEvent{
+			Time:       parse("09:00:00.000").Add(time.Duration(i) * time.Minute),
+			ID:         model.EventRegistered,
+			Competitor: competitorID,
+		})
+	}
+
+	order := func(startList []model.CompetitorEvent) []int {
+		var competitors []int
+		for _, event := range startList {
+			competitors = append(competitors, event.Competitor)
+		}
+		return competitors
+	}
+
+	t.Run("BibOrder", func(t *testing.T) {
+		startList, err := controller.Draw(registrations, config, controller.DrawOptions{Strategy: controller.DrawBibOrder}, timeFormat)
+		require.NoError(t, err)
+
+		assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8}, order(startList))
+		assert.Equal(t, model.CompetitorEvent{
+			Time:        parse("09:07:00.000"),
+			ID:          model.EventStartTimeSet,
+			Competitor:  2,
+			ExtraParams: "10:00:30.000",
+		}, startList[1])
+	})
+
+	t.Run("BibOrderFromRoster", func(t *testing.T) {
+		withRoster := config
+		withRoster.Roster = model.Roster{
+			1: {Bib: 31}, 2: {Bib: 12}, 3: {Bib: 44}, 4: {Bib: 7},
+			5: {Bib: 25}, 6: {Bib: 18}, 8: {Bib: 3},
+		}
+
+		startList, err := controller.Draw(registrations, withRoster, controller.DrawOptions{Strategy: controller.DrawBibOrder}, timeFormat)
+		require.NoError(t, err)
+
+		// Competitor 7 has no bib and starts last.
+		assert.Equal(t, []int{8, 4, 2, 6, 5, 1, 3, 7}, order(startList))
+	})
+
+	t.Run("RandomIsReproducible", func(t *testing.T) {
+		options := controller.DrawOptions{Strategy: controller.DrawRandom, Seed: 42}
+
+		first, err := controller.Draw(registrations, config, options, timeFormat)
+		require.NoError(t, err)
+		second, err := controller.Draw(registrations, config, options, timeFormat)
+		require.NoError(t, err)
+
+		assert.Equal(t, first, second)
+		assert.ElementsMatch(t, []int{1, 2, 3, 4, 5, 6, 7, 8}, order(first))
+	})
+
+	t.Run("SeededGroups", func(t *testing.T) {
+		options := controller.DrawOptions{Strategy: controller.DrawSeededGroups, Seed: 42, GroupSize: 3}
+
+		startList, err := controller.Draw(registrations, config, options, timeFormat)
+		require.NoError(t, err)
+
+		competitors := order(startList)
+		assert.ElementsMatch(t, []int{1, 2, 3}, competitors[0:3])
+		assert.ElementsMatch(t, []int{4, 5, 6}, competitors[3:6])
+		assert.ElementsMatch(t, []int{7, 8}, competitors[6:8])
+	})
+
+	t.Run("DrawTime", func(t *testing.T) {
+		options := controller.DrawOptions{Strategy: controller.DrawBibOrder, At: parse("09:45:00.000")}
+
+		startList, err := controller.Draw(registrations, config, options, timeFormat)
+		require.NoError(t, err)
+		assert.Equal(t, parse("09:45:00.000"), startList[0].Time)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		_, err := controller.Draw(nil, config, controller.DrawOptions{}, timeFormat)
+		assert.EqualError(t, err, "no registered competitors to draw")
+
+		_, err = controller.Draw(registrations, config, controller.DrawOptions{Strategy: "alphabet"}, timeFormat)
+		assert.EqualError(t, err, `unknown draw strategy "alphabet"`)
+
+		_, err = controller.Draw(registrations, config, controller.DrawOptions{Strategy: controller.DrawSeededGroups}, timeFormat)
+		assert.EqualError(t, err, "group size must be positive, got 0")
+	})
+}
@@ -0,0 +1,105 @@
+package _test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Maksim646/sunny_5_skiers/internal/durationfmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDurationLayout(t *testing.T) {
+	d := func(s string) time.Duration {
+		parsed, err := time.ParseDuration(s)
+		require.NoError(t, err)
+		return parsed
+	}
+
+	t.Run("Format", func(t *testing.T) {
+		tests := []struct {
+			layout   string
+			duration time.Duration
+			expected string
+		}{
+			{"hh:mm:ss.fff", d("5m0.04s"), "00:05:00.040"},
+			{"hh:mm:ss.fff", d("26h1m2.003s"), "26:01:02.003"},
+			{"m:ss.f", d("5m0.09s"), "5:00.0"},
+			{"m:ss.f", d("1h5m3.25s"), "65:03.2"},
+			{"[h:]mm:ss.f", d("5m3.25s"), "05:03.2"},
+			{"[h:]mm:ss.f", d("1h5m3.25s"), "1:05:03.2"},
+			{"s.ffffff", d("75.0012345s"), "75.001234"},
+			{"mm\\mss\\s", d("2m5s"), "02m05s"},
+			{"m:ss.f", -d("1m2.5s"), "-1:02.5"},
+		}
+
+		for _, tt := range tests {
+			layout, err := durationfmt.Compile(tt.layout)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, layout.Format(tt.duration), tt.layout)
+		}
+	})
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		durations := []time.Duration{0, d("0.5s"), d("59.999s"), d("5m0.04s"), d("1h5m3.25s"), d("26h1m2.003s"), -d("1m2.5s")}
+		layouts := []string{"hh:mm:ss.fff", "m:ss.fff", "[h:]mm:ss.fff", "[hh:][mm:]ss.fff", "s.fffffffff"}
+
+		for _, layoutRaw := range layouts {
+			layout := durationfmt.MustCompile(layoutRaw)
+			for _, duration := range durations {
+				parsed, err := layout.Parse(layout.Format(duration))
+				require.NoError(t, err, layoutRaw)
+				assert.Equal(t, duration, parsed, "%s %s", layoutRaw, layout.Format(duration))
+			}
+		}
+	})
+
+	t.Run("ParseTruncatesToLayout", func(t *testing.T) {
+		layout := durationfmt.MustCompile("[h:]mm:ss.f")
+		parsed, err := layout.Parse(layout.Format(d("1h5m3.25s")))
+		require.NoError(t, err)
+		assert.Equal(t, d("1h5m3.2s"), parsed)
+	})
+
+	t.Run("ParseErrors", func(t *testing.T) {
+		tests := []struct {
+			layout string
+			value  string
+		}{
+			{"hh:mm:ss.fff", "00:05:00"},
+			{"hh:mm:ss.fff", "00:75:00.000"},
+			{"m:ss.f", "5:0.0"},
+			{"m:ss.f", "5:00.0 "},
+			{"[h:]mm:ss.f", "a:05:00.0"},
+		}
+
+		for _, tt := range tests {
+			_, err := durationfmt.Parse(tt.layout, tt.value)
+			assert.Error(t, err, "%s %s", tt.layout, tt.value)
+		}
+	})
+
+	t.Run("InvalidLayouts", func(t *testing.T) {
+		tests := []struct {
+			layout string
+			err    string
+		}{
+			{"%02d:%02d:%02d.%03d", `duration layout "%02d:%02d:%02d.%03d" uses printf verbs, use tokens such as hh:mm:ss.fff`},
+			{"mm:ss:mm", `duration layout "mm:ss:mm": minutes appear twice`},
+			{"[h:mm:ss", `duration layout "[h:mm:ss": unclosed optional section`},
+			{"[[h:]]mm", `duration layout "[[h:]]mm": nested optional section`},
+			{"mm:ss]", `duration layout "mm:ss]": unexpected ]`},
+			{"[:]mm", `duration layout "[:]mm": optional section without a field`},
+			{"hhh:mm", `duration layout "hhh:mm": hours take one or two letters`},
+			{"ss.ffffffffff", `duration layout "ss.ffffffffff": at most nine fraction digits`},
+			{".fff", `duration layout ".fff" has no hours, minutes or seconds`},
+			{"hh:ss", `duration layout "hh:ss" has seconds without minutes`},
+			{"mm.fff", `duration layout "mm.fff" has fraction without seconds`},
+		}
+
+		for _, tt := range tests {
+			_, err := durationfmt.Compile(tt.layout)
+			assert.EqualError(t, err, tt.err)
+		}
+	})
+}
@@ -0,0 +1,123 @@
+package _test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Maksim646/sunny_5_skiers/internal/controller"
+	"github.com/Maksim646/sunny_5_skiers/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFollower(t *testing.T) {
+	dir := t.TempDir()
+	options := controller.FollowOptions{
+		EventsPath:            filepath.Join(dir, "events"),
+		OutputFilePath:        filepath.Join(dir, "output_events_log.txt"),
+		ResultTablePath:       filepath.Join(dir, "result_table.txt"),
+		TimeFormat:            "15:04:05.000",
+		ReportTableTimeFormat: "hh:mm:ss.fff",
+		TargetsInFireLine:     5,
+	}
+	config := model.Config{
+		Laps:        2,
+		LapLen:      3500,
+		PenaltyLen:  150,
+		FiringLines: 2,
+		StartDelta:  1 * time.Minute,
+	}
+
+	appendEvents := func(t *testing.T, content string) {
+		file, err := os.OpenFile(options.EventsPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		require.NoError(t, err)
+		_, err = file.WriteString(content)
+		require.NoError(t, err)
+		require.NoError(t, file.Close())
+	}
+
+	readFile := func(t *testing.T, path string) string {
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		return string(content)
+	}
+
+	follower, err := controller.NewFollower(config, options)
+	require.NoError(t, err)
+
+	t.Run("MissingFile", func(t *testing.T) {
+		require.NoError(t, follower.Step())
+		assert.Empty(t, readFile(t, options.OutputFilePath))
+		assert.Empty(t, readFile(t, options.ResultTablePath))
+	})
+
+	t.Run("PartialLine", func(t *testing.T) {
+		appendEvents(t, "[09:05:59.867] 1 1\n[09:15:00.841] 2 1 09:3")
+		require.NoError(t, follower.Step())
+		assert.Equal(t, "[09:05:59.867] The competitor(1) registered\n", readFile(t, options.OutputFilePath))
+
+		appendEvents(t, "0:00.000\n")
+		require.NoError(t, follower.Step())
+		assert.Equal(t, "[09:05:59.867] The competitor(1) registered\n"+
+			"[09:15:00.841] The start time for the competitor(1) was set by a draw to 09:30:00.000\n",
+			readFile(t, options.OutputFilePath))
+		assert.Equal(t, "[NotStarted] 1 [{,}, {,}] {,} 0/0 [{,}, {,}]\n", readFile(t, options.ResultTablePath))
+	})
+
+	t.Run("InvalidLineIsSkipped", func(t *testing.T) {
+		appendEvents(t, "[09:20:00.000] 42 1\n[09:29:45.734] 3 1\n")
+		require.NoError(t, follower.Step())
+		assert.Contains(t, readFile(t, options.OutputFilePath), "[09:29:45.734] The competitor(1) is on the start line\n")
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(options.EventsPath, []byte("[10:00:00.000] 1 2\n"), 0644))
+		require.NoError(t, follower.Step())
+		assert.Equal(t, "[10:00:00.000] The competitor(2) registered\n", readFile(t, options.OutputFilePath))
+		assert.Equal(t, "[NotStarted] 2 [{,}, {,}] {,} 0/0 [{,}, {,}]\n", readFile(t, options.ResultTablePath))
+	})
+
+	t.Run("Replaced", func(t *testing.T) {
+		replacement := filepath.Join(dir, "events.new")
+		require.NoError(t, os.WriteFile(replacement, []byte("[10:00:00.000] 1 3\n[10:00:01.000] 1 4\n"), 0644))
+		require.NoError(t, os.Rename(replacement, options.EventsPath))
+		require.NoError(t, follower.Step())
+		assert.Equal(t, "[10:00:00.000] The competitor(3) registered\n"+
+			"[10:00:01.000] The competitor(4) registered\n", readFile(t, options.OutputFilePath))
+	})
+
+	t.Run("RewrittenToSameSize", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(options.EventsPath, []byte("[10:00:00.000] 1 5\n[10:00:01.000] 1 6\n"), 0644))
+		require.NoError(t, follower.Step())
+		assert.Equal(t, "[10:00:00.000] The competitor(5) registered\n"+
+			"[10:00:01.000] The competitor(6) registered\n", readFile(t, options.OutputFilePath))
+	})
+
+	t.Run("FinishOnCancel", func(t *testing.T) {
+		finishDir := t.TempDir()
+		finishOptions := options
+		finishOptions.EventsPath = filepath.Join(finishDir, "events")
+		finishOptions.OutputFilePath = filepath.Join(finishDir, "output_events_log.txt")
+		finishOptions.ResultTablePath = filepath.Join(finishDir, "result_table.txt")
+		require.NoError(t, os.WriteFile(finishOptions.EventsPath, []byte(
+			"[09:00:00.000] 1 1\n[09:00:01.000] 1 2\n"+
+				"[09:10:00.000] 2 1 09:30:00.000\n[09:10:01.000] 2 2 09:31:00.000\n"+
+				"[09:29:00.000] 3 1\n[09:30:00.500] 4 1\n"), 0644))
+
+		finishFollower, err := controller.NewFollower(config, finishOptions)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		require.NoError(t, controller.Follow(ctx, finishFollower, time.Hour))
+
+		assert.Contains(t, readFile(t, finishOptions.OutputFilePath), "The competitor(2) is disqualified\n")
+		table := readFile(t, finishOptions.ResultTablePath)
+		assert.Contains(t, table, "[NotFinished] 1 ")
+		assert.Contains(t, table, "[NotStarted] 2 ")
+		assert.NotContains(t, table, "Running")
+	})
+}
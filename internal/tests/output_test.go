@@ -0,0 +1,187 @@
+package _test
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Maksim646/sunny_5_skiers/internal/controller"
+	"github.com/Maksim646/sunny_5_skiers/internal/durationfmt"
+	"github.com/Maksim646/sunny_5_skiers/internal/output"
+	"github.com/Maksim646/sunny_5_skiers/internal/race"
+	"github.com/Maksim646/sunny_5_skiers/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputFormats(t *testing.T) {
+	baseTime := time.Date(2025, time.May, 6, 10, 0, 0, 0, time.UTC)
+	config := model.Config{
+		Laps:        2,
+		LapLen:      3500,
+		PenaltyLen:  150,
+		FiringLines: 2,
+		Start:       baseTime,
+		StartDelta:  1 * time.Minute,
+		Roster: model.Roster{
+			1: {ID: 1, Bib: 11, Name: "Ivan Petrov", Nation: "RUS", Club: "Dynamo", Category: "Men", Gender: "M"},
+		},
+		Categories: map[string][]int{
+			"Men":   {3},
+			"Women": {2},
+		},
+	}
+
+	events := []model.CompetitorEvent{
+		{ID: 1, Competitor: 1, Time: baseTime},
+		{ID: 1, Competitor: 2, Time: baseTime},
+		{ID: 2, Competitor: 1, Time: baseTime.Add(10 * time.Second), ExtraParams: "10:00:30.000"},
+		{ID: 2, Competitor: 2, Time: baseTime.Add(10 * time.Second), ExtraParams: "10:01:30.000"},
+		{ID: 3, Competitor: 1, Time: baseTime.Add(20 * time.Second)},
+		{ID: 4, Competitor: 1, Time: baseTime.Add(30 * time.Second)},
+		{ID: 5, Competitor: 1, Time: baseTime.Add(40 * time.Second), ExtraParams: "1"},
+		{ID: 6, Competitor: 1, Time: baseTime.Add(50 * time.Second), ExtraParams: "1"},
+		{ID: 6, Competitor: 1, Time: baseTime.Add(60 * time.Second), ExtraParams: "2"},
+		{ID: 6, Competitor: 1, Time: baseTime.Add(70 * time.Second), ExtraParams: "4"},
+		{ID: 6, Competitor: 1, Time: baseTime.Add(80 * time.Second), ExtraParams: "5"},
+		{ID: 7, Competitor: 1, Time: baseTime.Add(90 * time.Second)},
+		{ID: 8, Competitor: 1, Time: baseTime.Add(100 * time.Second)},
+		{ID: 9, Competitor: 1, Time: baseTime.Add(110 * time.Second)},
+		{ID: 10, Competitor: 1, Time: baseTime.Add(120 * time.Second)},
+		{ID: 5, Competitor: 1, Time: baseTime.Add(130 * time.Second), ExtraParams: "1"},
+		{ID: 6, Competitor: 1, Time: baseTime.Add(140 * time.Second), ExtraParams: "1"},
+		{ID: 6, Competitor: 1, Time: baseTime.Add(150 * time.Second), ExtraParams: "2"},
+		{ID: 6, Competitor: 1, Time: baseTime.Add(160 * time.Second), ExtraParams: "3"},
+		{ID: 6, Competitor: 1, Time: baseTime.Add(170 * time.Second), ExtraParams: "4"},
+		{ID: 6, Competitor: 1, Time: baseTime.Add(175 * time.Second), ExtraParams: "5"},
+		{ID: 7, Competitor: 1, Time: baseTime.Add(180 * time.Second)},
+		{ID: 10, Competitor: 1, Time: baseTime.Add(210 * time.Second)},
+	}
+
+	tests := []struct {
+		name   string
+		format string
+	}{
+		{name: "JSON", format: output.FormatJSON},
+		{name: "CSV", format: output.FormatCSV},
+	}
+
+	for _, tt := range tests {
+		t.Run("ResultTable"+tt.name, func(t *testing.T) {
+			actualPath := "test_output/test_result_table_actual." + tt.format
+			expectedPath := "test_output/test_result_table_expected." + tt.format
+
+			defer os.Remove(actualPath)
+
+			engine := race.NewEngine(config, "15:04:05.000", 5)
+			applyEvents(t, engine, events)
+			engine.Finish()
+
+			err := controller.GenerateResultingTable(engine, actualPath, tt.format, "hh:mm:ss.fff")
+			require.NoError(t, err, "GenerateResultingTable returned error")
+
+			actualContent, err := os.ReadFile(actualPath)
+			require.NoError(t, err, "Cannot read actual result file")
+
+			expectedContent, err := os.ReadFile(expectedPath)
+			require.NoError(t, err, "Cannot read expected result file")
+
+			assert.Equal(t, string(expectedContent), string(actualContent), "Generated result table does not match expected output")
+		})
+
+		t.Run("Log"+tt.name, func(t *testing.T) {
+			actualPath := "test_output/test_log_actual." + tt.format
+			expectedPath := "test_output/test_log_expected." + tt.format
+
+			defer os.Remove(actualPath)
+
+			err := controller.ProcessEvents(race.NewEngine(config, "15:04:05.000", 5), events, actualPath, tt.format)
+			require.NoError(t, err, "ProcessEvents returned error")
+
+			actualContent, err := os.ReadFile(actualPath)
+			require.NoError(t, err, "Cannot read actual log file")
+
+			expectedContent, err := os.ReadFile(expectedPath)
+			require.NoError(t, err, "Cannot read expected log file")
+
+			assert.Equal(t, string(expectedContent), string(actualContent), "Log output does not match expected result")
+		})
+	}
+
+	t.Run("ResultTableHTML", func(t *testing.T) {
+		actualPath := "test_output/test_result_table_actual.html"
+		expectedPath := "test_output/test_result_table_expected.html"
+
+		defer os.Remove(actualPath)
+
+		events := append(events,
+			model.CompetitorEvent{ID: 1, Competitor: 3, Time: baseTime.Add(210 * time.Second)},
+			model.CompetitorEvent{ID: 2, Competitor: 3, Time: baseTime.Add(210 * time.Second), ExtraParams: "10:03:40.000"},
+			model.CompetitorEvent{ID: 4, Competitor: 3, Time: baseTime.Add(220 * time.Second)},
+			model.CompetitorEvent{ID: 10, Competitor: 3, Time: baseTime.Add(320 * time.Second)},
+			model.CompetitorEvent{ID: 10, Competitor: 3, Time: baseTime.Add(412 * time.Second)},
+		)
+
+		engine := race.NewEngine(config, "15:04:05.000", 5)
+		applyEvents(t, engine, events)
+		engine.Finish()
+
+		err := controller.GenerateResultingTable(engine, actualPath, output.FormatHTML, "hh:mm:ss.fff")
+		require.NoError(t, err, "GenerateResultingTable returned error")
+
+		actualContent, err := os.ReadFile(actualPath)
+		require.NoError(t, err, "Cannot read actual result file")
+
+		expectedContent, err := os.ReadFile(expectedPath)
+		require.NoError(t, err, "Cannot read expected result file")
+
+		assert.Equal(t, string(expectedContent), string(actualContent), "Generated result table does not match expected output")
+		assert.Contains(t, string(actualContent), "●●○●●")
+		assert.Contains(t, string(actualContent), "@media print")
+		assert.NotContains(t, string(actualContent), "<link")
+	})
+
+	t.Run("ResultTableHTMLGaps", func(t *testing.T) {
+		// Ranked at tenths, the gap is 0.1s although the times differ by 0.149s.
+		reports := []model.CompetitorReport{
+			{CompetitorID: 1, Status: model.CompetitorStarted, Place: 1, TotalTime: 5*time.Minute + 51*time.Millisecond},
+			{CompetitorID: 2, Status: model.CompetitorStarted, Place: 2, TotalTime: 5*time.Minute + 200*time.Millisecond, GapToLeader: 100 * time.Millisecond},
+		}
+
+		var page strings.Builder
+		require.NoError(t, output.WriteResultsHTML(&page, reports, model.Config{Laps: 1}, durationfmt.MustCompile("hh:mm:ss.fff")))
+
+		assert.Contains(t, page.String(), `<td class="gap">&#43;00:00:00.100</td>`)
+		assert.NotContains(t, page.String(), "00:00:00.149")
+	})
+
+	t.Run("JSONSchema", func(t *testing.T) {
+		engine := race.NewEngine(config, "15:04:05.000", 5)
+		applyEvents(t, engine, events)
+
+		data, err := json.Marshal(output.Results{SchemaVersion: output.SchemaVersion, Results: output.NewCompetitors(engine.Reports())})
+		require.NoError(t, err)
+
+		var results output.Results
+		require.NoError(t, json.Unmarshal(data, &results))
+		assert.Equal(t, 2, results.SchemaVersion)
+		require.Len(t, results.Results, 2)
+		assert.EqualValues(t, 180000, results.Results[0].TotalTimeMs)
+		assert.InDelta(t, 38.889, results.Results[0].Laps[0].Speed, 0.001)
+	})
+
+	t.Run("UnknownFormat", func(t *testing.T) {
+		engine := race.NewEngine(config, "15:04:05.000", 5)
+
+		err := controller.GenerateResultingTable(engine, "test_output/unused", "xml", "hh:mm:ss.fff")
+		assert.EqualError(t, err, `unknown output format "xml"`)
+
+		err = controller.ProcessEvents(engine, events, "test_output/unused", "xml")
+		assert.EqualError(t, err, `unknown output format "xml"`)
+
+		err = controller.ProcessEvents(engine, events, "test_output/unused", output.FormatHTML)
+		assert.EqualError(t, err, `unknown output format "html"`)
+	})
+}
@@ -3,6 +3,7 @@ package _test
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -24,10 +25,13 @@ func TestParseConfig(t *testing.T) {
 			LapLen:      3500,
 			PenaltyLen:  150,
 			FiringLines: 2,
+			Format:      model.FormatSprint,
 			StartRaw:    "10:00:00.000",
 			DeltaRaw:    "00:01:30",
 			Start:       startTime,
 			StartDelta:  time.Duration(1*time.Minute + 30*time.Second),
+			Rounding:    model.RoundingTruncate,
+			Date:        time.Date(0, time.January, 1, 0, 0, 0, 0, time.UTC),
 		}
 
 		assert.Equal(t, expected, config)
@@ -45,6 +49,29 @@ func TestParseConfig(t *testing.T) {
 		t.Logf("Expected error for invalid time format in config: %v", err)
 	})
 
+	t.Run("Individual format", func(t *testing.T) {
+		config, err := controller.ParseConfig("test_config/test_config_individual.json", "15:04:05.000", "15:04:05")
+		require.NoError(t, err)
+		assert.Equal(t, model.FormatIndividual, config.Format)
+		assert.Equal(t, time.Minute, config.PenaltyTime)
+		assert.Equal(t, 100*time.Millisecond, config.RankingResolution)
+	})
+
+	t.Run("Unknown format", func(t *testing.T) {
+		_, err := controller.ParseConfig("test_config/test_config_unknown_format.json", "15:04:05.000", "15:04:05")
+		assert.EqualError(t, err, `unknown competition format "relay"`)
+	})
+
+	t.Run("Duplicate category", func(t *testing.T) {
+		_, err := controller.ParseConfig("test_config/test_config_duplicate_category.json", "15:04:05.000", "15:04:05")
+		assert.EqualError(t, err, `competitor(2) is in categories "Juniors" and "Men"`)
+	})
+
+	t.Run("Overall category", func(t *testing.T) {
+		_, err := controller.ParseConfig("test_config/test_config_overall_category.json", "15:04:05.000", "15:04:05")
+		assert.EqualError(t, err, `category "Overall" is reserved for the overall ranking`)
+	})
+
 }
 
 func TestParseEvents(t *testing.T) {
@@ -93,6 +120,16 @@ func TestParseEvents(t *testing.T) {
 		}
 	})
 
+	t.Run("ExtendedTimestamps", func(t *testing.T) {
+		events, err := controller.ReadEvents(strings.NewReader(
+			"[2025-05-06T10:00:00.000Z] 1 1\n"+
+				"[2025-05-06T12:00:01.000+02:00] 1 2\n"), "events", timeFormat)
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		assert.True(t, events[0].Time.Equal(time.Date(2025, time.May, 6, 10, 0, 0, 0, time.UTC)))
+		assert.True(t, events[1].Time.Equal(time.Date(2025, time.May, 6, 10, 0, 1, 0, time.UTC)))
+	})
+
 	t.Run("NonExistentDirectory", func(t *testing.T) {
 		_, err := controller.ParseEvents("not_exist", timeFormat)
 		assert.Error(t, err)
@@ -116,4 +153,45 @@ func TestParseEvents(t *testing.T) {
 		assert.Error(t, err, "Expected error for invalid event line")
 	})
 
+	t.Run("ParseErrors", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			content string
+			line    int
+			reason  string
+		}{
+			{"TooFewFields", "[09:05:59.867] 1 1\n[09:15:00.841] 2\n", 2, "expected [time] eventID competitorID"},
+			{"NoBrackets", "09:05:59.867 1 1\n", 1, "time must be enclosed in brackets"},
+			{"InvalidTime", "[09:05] 1 1\n", 1, "invalid time"},
+			{"NoFraction", "[09:05:59] 1 1\n", 1, "invalid time"},
+			{"ShortFraction", "[09:05:59.8] 1 1\n", 1, "invalid time"},
+			{"ExtendedNoFraction", "[2025-05-06T09:05:59] 1 1\n", 1, "invalid time"},
+			{"UnknownEvent", "[09:05:59.867] 1 1\n\n[09:06:00.000] 14 1\n", 3, "unknown event ID 14"},
+			{"OutgoingEvent", "[09:05:59.867] 33 1\n", 1, "event 33 is outgoing and cannot be submitted"},
+			{"UnexpectedParams", "[09:05:59.867] 1 1 extra\n", 1, "event 1 takes no extra params, got \"extra\""},
+			{"MissingStartTime", "[09:05:59.867] 2 1\n", 1, "event 2 requires a start time"},
+			{"InvalidStartTime", "[09:05:59.867] 2 1 soon\n", 1, "event 2 has invalid start time \"soon\""},
+			{"InvalidFiringRange", "[09:05:59.867] 5 1 first\n", 1, "event 5 has invalid firing range number \"first\""},
+			{"MissingTarget", "[09:05:59.867] 6 1\n", 1, "event 6 requires a target number"},
+			{"MissingComment", "[09:05:59.867] 11 1\n", 1, "event 11 requires a comment"},
+			{"InvalidCompetitor", "[09:05:59.867] 1 0\n", 1, "competitor ID must be positive, got 0"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				path := filepath.Join(t.TempDir(), "events")
+				require.NoError(t, os.WriteFile(path, []byte(tt.content), 0644))
+
+				events, err := controller.ParseEvents(path, timeFormat)
+				assert.Nil(t, events)
+
+				var parseErr *controller.ParseError
+				require.ErrorAs(t, err, &parseErr)
+				assert.Equal(t, path, parseErr.File)
+				assert.Equal(t, tt.line, parseErr.Line)
+				assert.Equal(t, tt.reason, parseErr.Reason)
+			})
+		}
+	})
+
 }
@@ -0,0 +1,139 @@
+package _test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Maksim646/sunny_5_skiers/internal/controller"
+	"github.com/Maksim646/sunny_5_skiers/internal/output"
+	"github.com/Maksim646/sunny_5_skiers/internal/race"
+	"github.com/Maksim646/sunny_5_skiers/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrecision(t *testing.T) {
+	baseTime := time.Date(2025, time.May, 6, 10, 0, 0, 0, time.UTC)
+	config := model.Config{
+		Laps:        1,
+		LapLen:      3000,
+		PenaltyLen:  150,
+		FiringLines: 0,
+		Start:       baseTime,
+		StartDelta:  1 * time.Minute,
+	}
+
+	// finish runs competitors 1 and 2 with the given lap times.
+	finish := func(config model.Config, lapTimes ...time.Duration) *race.Engine {
+		engine := race.NewEngine(config, "15:04:05.000", 5)
+		for i, lapTime := range lapTimes {
+			id := i + 1
+			events := []model.CompetitorEvent{
+				{ID: 1, Competitor: id, Time: baseTime},
+				{ID: 2, Competitor: id, Time: baseTime, ExtraParams: "10:00:30.000"},
+				{ID: 4, Competitor: id, Time: baseTime.Add(30 * time.Second)},
+				{ID: 10, Competitor: id, Time: baseTime.Add(30*time.Second + lapTime)},
+			}
+			for _, event := range events {
+				_, err := engine.Apply(event)
+				require.NoError(t, err)
+			}
+		}
+		engine.Finish()
+		return engine
+	}
+
+	places := func(engine *race.Engine) map[int]int {
+		places := make(map[int]int)
+		for _, report := range engine.Reports() {
+			places[report.CompetitorID] = report.Place
+		}
+		return places
+	}
+
+	t.Run("SubMillisecondInput", func(t *testing.T) {
+		eventTime, err := controller.ParseEventTime("09:05:59.867250", "15:04:05.000")
+		require.NoError(t, err)
+		assert.Equal(t, 867250*time.Microsecond, time.Duration(eventTime.Nanosecond()))
+
+		eventTime, err = controller.ParseEventTime("2025-05-06T09:05:59.8672+02:00", "15:04:05.000")
+		require.NoError(t, err)
+		assert.Equal(t, 867200*time.Microsecond, time.Duration(eventTime.Nanosecond()))
+
+		eventTime, err = controller.ParseEventTime("09:05:59.8672501239", "15:04:05.000")
+		require.NoError(t, err)
+		assert.Equal(t, 867250123*time.Nanosecond, time.Duration(eventTime.Nanosecond()))
+
+		for _, short := range []string{"09:05:59", "09:05:59.8", "09:05:59.86", "09:05:59.", "2025-05-06T09:05:59", "2025-05-06T09:05:59.8+02:00"} {
+			_, err := controller.ParseEventTime(short, "15:04:05.000")
+			assert.Error(t, err, short)
+		}
+	})
+
+	t.Run("InputResolution", func(t *testing.T) {
+		rounded := config
+		rounded.InputResolution = time.Millisecond
+		rounded.Rounding = model.RoundingRound
+
+		engine := race.NewEngine(rounded, "15:04:05.000", 5)
+		event := model.CompetitorEvent{ID: 1, Competitor: 1, Time: baseTime.Add(600 * time.Microsecond)}
+		outputEvents, err := engine.Apply(event)
+		require.NoError(t, err)
+		assert.Equal(t, baseTime.Add(time.Millisecond), outputEvents[0].Time)
+	})
+
+	t.Run("RankingRounding", func(t *testing.T) {
+		lapTimes := []time.Duration{5*time.Minute + 40*time.Millisecond, 5*time.Minute + 60*time.Millisecond}
+
+		truncated := config
+		truncated.RankingResolution = 100 * time.Millisecond
+		truncated.Rounding = model.RoundingTruncate
+		assert.Equal(t, map[int]int{1: 1, 2: 1}, places(finish(truncated, lapTimes...)))
+
+		rounded := truncated
+		rounded.Rounding = model.RoundingRound
+		assert.Equal(t, map[int]int{1: 1, 2: 2}, places(finish(rounded, lapTimes...)))
+	})
+
+	t.Run("DisplayResolution", func(t *testing.T) {
+		displayed := config
+		displayed.DisplayResolution = 100 * time.Millisecond
+		displayed.Rounding = model.RoundingRound
+
+		engine := finish(displayed, 5*time.Minute+40*time.Millisecond, 5*time.Minute+60*time.Millisecond)
+		reports := engine.Reports()
+		assert.Equal(t, map[int]int{1: 1, 2: 2}, places(engine), "ranking keeps the full precision")
+
+		shown := output.AtDisplayResolution(reports, displayed)
+		assert.Equal(t, 5*time.Minute, shown[0].TotalTime)
+		assert.Equal(t, 5*time.Minute+100*time.Millisecond, shown[1].TotalTime)
+		assert.Equal(t, 5*time.Minute+100*time.Millisecond, shown[1].Laps[0].Time)
+		assert.Equal(t, 0*time.Millisecond, shown[1].GapToLeader)
+		assert.Equal(t, 5*time.Minute+60*time.Millisecond, reports[1].TotalTime, "reports are not modified")
+	})
+
+	t.Run("ConfigErrors", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			content string
+			err     string
+		}{
+			{"UnknownRounding", `"rounding": "ceil"`, `unknown rounding "ceil"`},
+			{"NegativeDisplayResolution", `"displayResolution": "-100ms"`, "display resolution must be positive, got -100ms"},
+			{"ZeroInputResolution", `"inputResolution": "0s"`, "input resolution must be positive, got 0s"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				path := filepath.Join(t.TempDir(), "config.json")
+				content := `{"start": "10:00:00.000", "startDelta": "00:01:00", ` + tt.content + `}`
+				require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+				_, err := controller.ParseConfig(path, "15:04:05.000", "15:04:05")
+				assert.EqualError(t, err, tt.err)
+			})
+		}
+	})
+}
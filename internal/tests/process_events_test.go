@@ -1,11 +1,16 @@
 package _test
 
 import (
+	"bytes"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/Maksim646/sunny_5_skiers/internal/controller"
+	"github.com/Maksim646/sunny_5_skiers/internal/output"
+	"github.com/Maksim646/sunny_5_skiers/internal/race"
 	"github.com/Maksim646/sunny_5_skiers/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -14,11 +19,19 @@ import (
 func TestProcessEvents(t *testing.T) {
 	baseTime := time.Date(2025, time.May, 6, 10, 0, 0, 0, time.UTC)
 	timeFormat := "15:04:05.000"
+	config := model.Config{
+		Laps:        2,
+		LapLen:      3500,
+		PenaltyLen:  150,
+		FiringLines: 2,
+		Start:       baseTime,
+		StartDelta:  1 * time.Minute,
+	}
 
 	t.Run("Valid", func(t *testing.T) {
 		events := []model.CompetitorEvent{
 			{ID: 1, Competitor: 1, Time: baseTime},
-			{ID: 2, Competitor: 1, Time: baseTime.Add(10 * time.Second), ExtraParams: "09:30:00.000"},
+			{ID: 2, Competitor: 1, Time: baseTime.Add(10 * time.Second), ExtraParams: "10:00:30.000"},
 			{ID: 3, Competitor: 1, Time: baseTime.Add(20 * time.Second)},
 			{ID: 4, Competitor: 1, Time: baseTime.Add(40 * time.Second)},
 			{ID: 5, Competitor: 1, Time: baseTime.Add(50 * time.Second), ExtraParams: "1"},
@@ -46,7 +59,7 @@ func TestProcessEvents(t *testing.T) {
 
 		defer os.Remove(actualPath)
 
-		err := controller.ProcessEvents(events, actualPath, timeFormat)
+		err := controller.ProcessEvents(race.NewEngine(config, timeFormat, 5), events, actualPath, output.FormatText)
 		require.NoError(t, err)
 
 		actualContent, err := os.ReadFile(actualPath)
@@ -66,7 +79,7 @@ func TestProcessEvents(t *testing.T) {
 
 		events := []model.CompetitorEvent{
 			{ID: 1, Competitor: 1, Time: baseTime},
-			{ID: 2, Competitor: 1, Time: baseTime.Add(10 * time.Second), ExtraParams: "09:30:00.000"},
+			{ID: 2, Competitor: 1, Time: baseTime.Add(10 * time.Second), ExtraParams: "10:00:30.000"},
 			{ID: 3, Competitor: 1, Time: baseTime.Add(20 * time.Second)},
 			{ID: 4, Competitor: 1, Time: baseTime.Add(30 * time.Second)},
 			{ID: 5, Competitor: 1, Time: baseTime.Add(40 * time.Second), ExtraParams: "1"},
@@ -78,7 +91,35 @@ func TestProcessEvents(t *testing.T) {
 			{ID: 11, Competitor: 1, Time: baseTime.Add(100 * time.Second), ExtraParams: "Lost in the forest"},
 		}
 
-		err := controller.ProcessEvents(events, actualPath, timeFormat)
+		err := controller.ProcessEvents(race.NewEngine(config, timeFormat, 5), events, actualPath, output.FormatText)
+		require.NoError(t, err)
+
+		actualContent, err := os.ReadFile(actualPath)
+		require.NoError(t, err)
+
+		expectedContent, err := os.ReadFile(expectedPath)
+		require.NoError(t, err, "Cannot read expected log file")
+
+		assert.Equal(t, string(expectedContent), string(actualContent), "Log output does not match expected result")
+	})
+
+	t.Run("Disqualified", func(t *testing.T) {
+		actualPath := "test_process_events/test_disqualified_log.txt"
+		expectedPath := "test_process_events/test_disqualified_log_expected.txt"
+
+		defer os.Remove(actualPath)
+
+		events := []model.CompetitorEvent{
+			{ID: 1, Competitor: 1, Time: baseTime},
+			{ID: 1, Competitor: 2, Time: baseTime.Add(5 * time.Second)},
+			{ID: 2, Competitor: 1, Time: baseTime.Add(10 * time.Second), ExtraParams: "10:00:30.000"},
+			{ID: 2, Competitor: 2, Time: baseTime.Add(15 * time.Second), ExtraParams: "10:01:00.000"},
+			{ID: 3, Competitor: 1, Time: baseTime.Add(20 * time.Second)},
+			{ID: 4, Competitor: 1, Time: baseTime.Add(120 * time.Second)},
+			{ID: 10, Competitor: 1, Time: baseTime.Add(150 * time.Second)},
+		}
+
+		err := controller.ProcessEvents(race.NewEngine(config, timeFormat, 5), events, actualPath, output.FormatText)
 		require.NoError(t, err)
 
 		actualContent, err := os.ReadFile(actualPath)
@@ -98,8 +139,8 @@ func TestGenerateResultingTable(t *testing.T) {
 	t.Run("Valid", func(t *testing.T) {
 		events := []model.CompetitorEvent{
 			{ID: 1, Competitor: 1, Time: baseTime},
-			{ID: 2, Competitor: 1, Time: baseTime.Add(10 * time.Second), ExtraParams: "09:30:00.000"},
-			{ID: 3, Competitor: 1, Time: baseTime.Add(20 * time.Second), ExtraParams: "09:30:00.000"},
+			{ID: 2, Competitor: 1, Time: baseTime.Add(10 * time.Second), ExtraParams: "10:00:30.000"},
+			{ID: 3, Competitor: 1, Time: baseTime.Add(20 * time.Second), ExtraParams: "10:00:30.000"},
 			{ID: 4, Competitor: 1, Time: baseTime.Add(30 * time.Second)},
 			{ID: 5, Competitor: 1, Time: baseTime.Add(40 * time.Second), ExtraParams: "1"},
 			{ID: 6, Competitor: 1, Time: baseTime.Add(50 * time.Second), ExtraParams: "1"},
@@ -135,7 +176,10 @@ func TestGenerateResultingTable(t *testing.T) {
 
 		defer os.Remove(actualPath)
 
-		err := controller.GenerateResultingTable(events, actualPath, "%02d:%02d:%02d.%03d", config, 5)
+		engine := race.NewEngine(config, "15:04:05.000", 5)
+		applyEvents(t, engine, events)
+
+		err := controller.GenerateResultingTable(engine, actualPath, output.FormatText, "hh:mm:ss.fff")
 		require.NoError(t, err, "GenerateResultingTable returned error")
 
 		actualContent, err := os.ReadFile(actualPath)
@@ -150,8 +194,8 @@ func TestGenerateResultingTable(t *testing.T) {
 	t.Run("NotStarted", func(t *testing.T) {
 		events := []model.CompetitorEvent{
 			{ID: 1, Competitor: 1, Time: baseTime},
-			{ID: 2, Competitor: 1, Time: baseTime.Add(10 * time.Second), ExtraParams: "09:30:00.000"},
-			{ID: 3, Competitor: 1, Time: baseTime.Add(20 * time.Second), ExtraParams: "09:30:00.000"},
+			{ID: 2, Competitor: 1, Time: baseTime.Add(10 * time.Second), ExtraParams: "10:00:30.000"},
+			{ID: 3, Competitor: 1, Time: baseTime.Add(20 * time.Second), ExtraParams: "10:00:30.000"},
 		}
 
 		config := model.Config{
@@ -168,7 +212,10 @@ func TestGenerateResultingTable(t *testing.T) {
 
 		defer os.Remove(actualPath)
 
-		err := controller.GenerateResultingTable(events, actualPath, "%02d:%02d:%02d.%03d", config, 5)
+		engine := race.NewEngine(config, "15:04:05.000", 5)
+		applyEvents(t, engine, events)
+
+		err := controller.GenerateResultingTable(engine, actualPath, output.FormatText, "hh:mm:ss.fff")
 		require.NoError(t, err, "GenerateResultingTable returned error")
 
 		actualContent, err := os.ReadFile(actualPath)
@@ -184,8 +231,8 @@ func TestGenerateResultingTable(t *testing.T) {
 	t.Run("NotFinished", func(t *testing.T) {
 		events := []model.CompetitorEvent{
 			{ID: 1, Competitor: 1, Time: baseTime},
-			{ID: 2, Competitor: 1, Time: baseTime.Add(10 * time.Second), ExtraParams: "09:30:00.000"},
-			{ID: 3, Competitor: 1, Time: baseTime.Add(20 * time.Second), ExtraParams: "09:30:00.000"},
+			{ID: 2, Competitor: 1, Time: baseTime.Add(10 * time.Second), ExtraParams: "10:00:30.000"},
+			{ID: 3, Competitor: 1, Time: baseTime.Add(20 * time.Second), ExtraParams: "10:00:30.000"},
 			{ID: 4, Competitor: 1, Time: baseTime.Add(30 * time.Second)},
 			{ID: 11, Competitor: 1, Time: baseTime.Add(40 * time.Second), ExtraParams: "He was too tired and went home"},
 		}
@@ -204,7 +251,10 @@ func TestGenerateResultingTable(t *testing.T) {
 
 		defer os.Remove(actualPath)
 
-		err := controller.GenerateResultingTable(events, actualPath, "%02d:%02d:%02d.%03d", config, 5)
+		engine := race.NewEngine(config, "15:04:05.000", 5)
+		applyEvents(t, engine, events)
+
+		err := controller.GenerateResultingTable(engine, actualPath, output.FormatText, "hh:mm:ss.fff")
 		require.NoError(t, err, "GenerateResultingTable returned error")
 
 		actualContent, err := os.ReadFile(actualPath)
@@ -218,3 +268,60 @@ func TestGenerateResultingTable(t *testing.T) {
 	})
 
 }
+
+func TestProcessEventsInMemory(t *testing.T) {
+	timeFormat := "15:04:05.000"
+	configJSON := `{"laps": 1, "lapLen": 3000, "penaltyLen": 150, "firingLines": 1, "start": "10:00:00.000", "startDelta": "00:01:00"}`
+	eventLines := "[09:50:00.000] 1 1\n" +
+		"[09:55:00.000] 2 1 10:00:00.000\n" +
+		"[09:59:50.000] 3 1\n" +
+		"[10:00:01.000] 4 1\n" +
+		"[10:02:00.000] 5 1 1\n" +
+		"[10:02:10.000] 6 1 1\n" +
+		"[10:02:20.000] 7 1\n" +
+		"[10:05:00.000] 10 1\n"
+
+	config, err := controller.ReadConfig(strings.NewReader(configJSON), timeFormat, "15:04:05")
+	require.NoError(t, err)
+	events, err := controller.ReadEvents(strings.NewReader(eventLines), "events", timeFormat)
+	require.NoError(t, err)
+
+	t.Run("OutputLog", func(t *testing.T) {
+		var log bytes.Buffer
+		require.NoError(t, controller.ProcessEventsTo(&log, race.NewEngine(config, timeFormat, 5), events, output.FormatText))
+
+		path := filepath.Join(t.TempDir(), "log.txt")
+		require.NoError(t, controller.ProcessEvents(race.NewEngine(config, timeFormat, 5), events, path, output.FormatText))
+		fromFile, err := os.ReadFile(path)
+		require.NoError(t, err)
+
+		assert.Equal(t, string(fromFile), log.String())
+		assert.Contains(t, log.String(), "[10:05:00.000] The competitor(1) has finished\n")
+	})
+
+	t.Run("ResultTable", func(t *testing.T) {
+		engine := race.NewEngine(config, timeFormat, 5)
+		applyEvents(t, engine, events)
+
+		var table bytes.Buffer
+		require.NoError(t, controller.WriteResultingTable(&table, engine, output.FormatText, "hh:mm:ss.fff"))
+		assert.Equal(t, "[00:05:00.000] 1 [{00:05:00.000, 10.000}] {,} missed:4 1/5 [{1, 10000, 00:00:20.000}]\n", table.String())
+	})
+
+	t.Run("UnknownFormat", func(t *testing.T) {
+		var log bytes.Buffer
+		err := controller.ProcessEventsTo(&log, race.NewEngine(config, timeFormat, 5), events, output.FormatHTML)
+		assert.EqualError(t, err, `unknown output format "html"`)
+		assert.Zero(t, log.Len())
+	})
+}
+
+func applyEvents(t *testing.T, engine *race.Engine, events []model.CompetitorEvent) {
+	t.Helper()
+
+	for _, event := range events {
+		_, err := engine.Apply(event)
+		require.NoError(t, err)
+	}
+	engine.Finish()
+}
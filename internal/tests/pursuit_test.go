@@ -0,0 +1,62 @@
+package _test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Maksim646/sunny_5_skiers/internal/controller"
+	"github.com/Maksim646/sunny_5_skiers/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPursuitStartList(t *testing.T) {
+	timeFormat := "15:04:05.000"
+
+	parse := func(ts string) time.Time {
+		tm, err := time.Parse(timeFormat, ts)
+		require.NoError(t, err)
+		return tm
+	}
+
+	reports := []model.CompetitorReport{
+		{CompetitorID: 4, Status: model.CompetitorStarted, TotalTime: 25*time.Minute + 10*time.Second},
+		{CompetitorID: 2, Status: model.CompetitorStarted, TotalTime: 25*time.Minute + 42*time.Second + 500*time.Millisecond},
+		{CompetitorID: 7, Status: model.CompetitorStarted, TotalTime: 29 * time.Minute},
+		{CompetitorID: 1, Status: model.CompetitorNotFinished},
+		{CompetitorID: 3, Status: model.CompetitorNotStarted},
+	}
+
+	t.Run("GapsFromResults", func(t *testing.T) {
+		startList := controller.PursuitStartList(reports, parse("11:00:00.000"), parse("10:30:00.000"), 0, timeFormat)
+
+		assert.Equal(t, []model.CompetitorEvent{
+			{Time: parse("10:30:00.000"), ID: 2, Competitor: 4, ExtraParams: "11:00:00.000"},
+			{Time: parse("10:30:00.000"), ID: 2, Competitor: 2, ExtraParams: "11:00:32.500"},
+			{Time: parse("10:30:00.000"), ID: 2, Competitor: 7, ExtraParams: "11:03:50.000"},
+		}, startList)
+	})
+
+	t.Run("CappedGap", func(t *testing.T) {
+		startList := controller.PursuitStartList(reports, parse("11:00:00.000"), parse("10:30:00.000"), 3*time.Minute, timeFormat)
+
+		require.Len(t, startList, 3)
+		assert.Equal(t, "11:03:00.000", startList[2].ExtraParams)
+	})
+
+	t.Run("ReadableByParseEvents", func(t *testing.T) {
+		startList := controller.PursuitStartList(reports, parse("11:00:00.000"), parse("10:30:00.000"), 0, timeFormat)
+
+		path := filepath.Join(t.TempDir(), "pursuit")
+		file, err := os.Create(path)
+		require.NoError(t, err)
+		require.NoError(t, controller.WriteEvents(file, startList, timeFormat))
+		require.NoError(t, file.Close())
+
+		events, err := controller.ParseEvents(path, timeFormat)
+		require.NoError(t, err)
+		assert.Equal(t, startList, events)
+	})
+}
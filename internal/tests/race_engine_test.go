@@ -0,0 +1,359 @@
+package _test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Maksim646/sunny_5_skiers/internal/race"
+	"github.com/Maksim646/sunny_5_skiers/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine(t *testing.T) {
+	baseTime := time.Date(2025, time.May, 6, 10, 0, 0, 0, time.UTC)
+	config := model.Config{
+		Laps:        1,
+		LapLen:      3000,
+		PenaltyLen:  150,
+		FiringLines: 1,
+		Start:       baseTime,
+		StartDelta:  1 * time.Minute,
+	}
+
+	t.Run("LiveSnapshot", func(t *testing.T) {
+		engine := race.NewEngine(config, "15:04:05.000", 5)
+
+		events := []model.CompetitorEvent{
+			{ID: 1, Competitor: 1, Time: baseTime},
+			{ID: 2, Competitor: 1, Time: baseTime.Add(10 * time.Second), ExtraParams: "10:00:30.000"},
+			{ID: 3, Competitor: 1, Time: baseTime.Add(20 * time.Second)},
+			{ID: 4, Competitor: 1, Time: baseTime.Add(30 * time.Second)},
+		}
+		for _, event := range events {
+			outputEvents, err := engine.Apply(event)
+			require.NoError(t, err)
+			assert.Equal(t, []model.CompetitorEvent{event}, outputEvents)
+		}
+
+		report, ok := engine.Report(1)
+		require.True(t, ok)
+		assert.Equal(t, model.CompetitorRunning, report.Status)
+		assert.Empty(t, report.Laps)
+
+		lap := model.CompetitorEvent{ID: 10, Competitor: 1, Time: baseTime.Add(330 * time.Second)}
+		outputEvents, err := engine.Apply(lap)
+		require.NoError(t, err)
+		assert.Equal(t, []model.CompetitorEvent{
+			lap,
+			{ID: model.EventFinished, Competitor: 1, Time: lap.Time},
+		}, outputEvents)
+
+		report, ok = engine.Report(1)
+		require.True(t, ok)
+		assert.Equal(t, 5*time.Minute, report.TotalTime)
+		require.Len(t, report.Laps, 1)
+		assert.InDelta(t, 10.0, report.Laps[0].Speed, 0.001)
+	})
+
+	t.Run("MeasuredFromScheduledStart", func(t *testing.T) {
+		engine := race.NewEngine(config, "15:04:05.000", 5)
+
+		events := []model.CompetitorEvent{
+			{ID: 1, Competitor: 1, Time: baseTime},
+			{ID: 2, Competitor: 1, Time: baseTime.Add(10 * time.Second), ExtraParams: "10:00:30.000"},
+			{ID: 3, Competitor: 1, Time: baseTime.Add(20 * time.Second)},
+			{ID: 4, Competitor: 1, Time: baseTime.Add(45 * time.Second)},
+			{ID: 10, Competitor: 1, Time: baseTime.Add(330 * time.Second)},
+		}
+		for _, event := range events {
+			_, err := engine.Apply(event)
+			require.NoError(t, err)
+		}
+
+		report, ok := engine.Report(1)
+		require.True(t, ok)
+		assert.Equal(t, 5*time.Minute, report.TotalTime)
+		assert.Equal(t, 15*time.Second, report.StartLag)
+		require.Len(t, report.Laps, 1)
+		assert.Equal(t, 5*time.Minute, report.Laps[0].Time)
+	})
+
+	t.Run("FiringRangeVisits", func(t *testing.T) {
+		engine := race.NewEngine(config, "15:04:05.000", 5)
+
+		events := []model.CompetitorEvent{
+			{ID: 1, Competitor: 1, Time: baseTime},
+			{ID: 2, Competitor: 1, Time: baseTime.Add(10 * time.Second), ExtraParams: "10:00:30.000"},
+			{ID: 3, Competitor: 1, Time: baseTime.Add(20 * time.Second)},
+			{ID: 4, Competitor: 1, Time: baseTime.Add(30 * time.Second)},
+			{ID: 5, Competitor: 1, Time: baseTime.Add(60 * time.Second), ExtraParams: "3"},
+			{ID: 6, Competitor: 1, Time: baseTime.Add(62 * time.Second), ExtraParams: "2"},
+			{ID: 6, Competitor: 1, Time: baseTime.Add(64 * time.Second), ExtraParams: "5"},
+			{ID: 7, Competitor: 1, Time: baseTime.Add(75 * time.Second)},
+		}
+		for _, event := range events {
+			_, err := engine.Apply(event)
+			require.NoError(t, err)
+		}
+
+		report, ok := engine.Report(1)
+		require.True(t, ok)
+		assert.Equal(t, []model.FiringRangeVisit{
+			{FiringRange: 3, Position: model.PositionProne, Targets: 5, HitTargets: []int{2, 5}, Misses: 3, Time: 15 * time.Second},
+		}, report.FiringRanges)
+		assert.Equal(t, 2, report.Hits)
+		assert.Equal(t, 5, report.Shots)
+	})
+
+	t.Run("PenaltyLoops", func(t *testing.T) {
+		engine := race.NewEngine(config, "15:04:05.000", 5)
+
+		events := []model.CompetitorEvent{
+			{ID: 1, Competitor: 1, Time: baseTime},
+			{ID: 2, Competitor: 1, Time: baseTime.Add(10 * time.Second), ExtraParams: "10:00:30.000"},
+			{ID: 3, Competitor: 1, Time: baseTime.Add(20 * time.Second)},
+			{ID: 4, Competitor: 1, Time: baseTime.Add(30 * time.Second)},
+			{ID: 5, Competitor: 1, Time: baseTime.Add(60 * time.Second), ExtraParams: "1"},
+			{ID: 6, Competitor: 1, Time: baseTime.Add(62 * time.Second), ExtraParams: "1"},
+			{ID: 6, Competitor: 1, Time: baseTime.Add(64 * time.Second), ExtraParams: "2"},
+			{ID: 7, Competitor: 1, Time: baseTime.Add(70 * time.Second)},
+			{ID: 8, Competitor: 1, Time: baseTime.Add(80 * time.Second)},
+			{ID: 9, Competitor: 1, Time: baseTime.Add(140 * time.Second)},
+			{ID: 5, Competitor: 1, Time: baseTime.Add(200 * time.Second), ExtraParams: "2"},
+			{ID: 6, Competitor: 1, Time: baseTime.Add(202 * time.Second), ExtraParams: "1"},
+			{ID: 6, Competitor: 1, Time: baseTime.Add(204 * time.Second), ExtraParams: "2"},
+			{ID: 6, Competitor: 1, Time: baseTime.Add(206 * time.Second), ExtraParams: "3"},
+			{ID: 6, Competitor: 1, Time: baseTime.Add(208 * time.Second), ExtraParams: "4"},
+			{ID: 7, Competitor: 1, Time: baseTime.Add(210 * time.Second)},
+			{ID: 10, Competitor: 1, Time: baseTime.Add(330 * time.Second)},
+		}
+		for _, event := range events {
+			_, err := engine.Apply(event)
+			require.NoError(t, err)
+		}
+
+		report, ok := engine.Report(1)
+		require.True(t, ok)
+		assert.Equal(t, 4, report.PenaltyLoops)
+		assert.Equal(t, 3, report.PenaltyLoopsRun)
+		assert.Equal(t, 1, report.MissedPenaltyLoops)
+		assert.Equal(t, time.Minute, report.PenaltyTime)
+		assert.Equal(t, 450, report.PenaltyDistance)
+		assert.InDelta(t, 7.5, report.PenaltySpeed, 0.001)
+		assert.Equal(t, []model.LapInfo{{Time: time.Minute, Speed: 7.5}}, report.PenaltyLaps)
+	})
+
+	t.Run("PenaltyLoopsLimitedByStay", func(t *testing.T) {
+		limited := config
+		limited.MaxPenaltySpeed = 10
+		engine := race.NewEngine(limited, "15:04:05.000", 5)
+
+		// Three misses owe three loops of 150m, the 30s stay allows two at 10 m/s.
+		events := []model.CompetitorEvent{
+			{ID: 1, Competitor: 1, Time: baseTime},
+			{ID: 2, Competitor: 1, Time: baseTime.Add(10 * time.Second), ExtraParams: "10:00:30.000"},
+			{ID: 3, Competitor: 1, Time: baseTime.Add(20 * time.Second)},
+			{ID: 4, Competitor: 1, Time: baseTime.Add(30 * time.Second)},
+			{ID: 5, Competitor: 1, Time: baseTime.Add(60 * time.Second), ExtraParams: "1"},
+			{ID: 6, Competitor: 1, Time: baseTime.Add(62 * time.Second), ExtraParams: "1"},
+			{ID: 6, Competitor: 1, Time: baseTime.Add(64 * time.Second), ExtraParams: "2"},
+			{ID: 7, Competitor: 1, Time: baseTime.Add(70 * time.Second)},
+			{ID: 8, Competitor: 1, Time: baseTime.Add(80 * time.Second)},
+			{ID: 9, Competitor: 1, Time: baseTime.Add(110 * time.Second)},
+			{ID: 10, Competitor: 1, Time: baseTime.Add(330 * time.Second)},
+		}
+		for _, event := range events {
+			_, err := engine.Apply(event)
+			require.NoError(t, err)
+		}
+
+		report, ok := engine.Report(1)
+		require.True(t, ok)
+		assert.Equal(t, 3, report.PenaltyLoops)
+		assert.Equal(t, 2, report.PenaltyLoopsRun)
+		assert.Equal(t, 1, report.MissedPenaltyLoops)
+		assert.Equal(t, 300, report.PenaltyDistance)
+		assert.InDelta(t, 10, report.PenaltySpeed, 0.001)
+	})
+
+	t.Run("FinishMarksRunningAsNotFinished", func(t *testing.T) {
+		engine := race.NewEngine(config, "15:04:05.000", 5)
+
+		events := []model.CompetitorEvent{
+			{ID: 1, Competitor: 1, Time: baseTime},
+			{ID: 1, Competitor: 2, Time: baseTime},
+			{ID: 2, Competitor: 1, Time: baseTime.Add(10 * time.Second), ExtraParams: "10:00:30.000"},
+			{ID: 2, Competitor: 2, Time: baseTime.Add(10 * time.Second), ExtraParams: "10:01:00.000"},
+			{ID: 4, Competitor: 1, Time: baseTime.Add(30 * time.Second)},
+		}
+		for _, event := range events {
+			_, err := engine.Apply(event)
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, []model.CompetitorEvent{
+			{ID: model.EventDisqualified, Competitor: 2, Time: baseTime.Add(2 * time.Minute)},
+		}, engine.Finish())
+
+		reports := engine.Reports()
+		require.Len(t, reports, 2)
+		assert.Equal(t, model.CompetitorNotFinished, reports[0].Status)
+		assert.Equal(t, model.CompetitorNotStarted, reports[1].Status)
+	})
+
+	t.Run("DisqualifiedOnParsedClock", func(t *testing.T) {
+		engine := race.NewEngine(config, "15:04:05.000", 5)
+
+		parse := func(ts string) time.Time {
+			tm, err := time.Parse("15:04:05.000", ts)
+			require.NoError(t, err)
+			return tm
+		}
+
+		_, err := engine.Apply(model.CompetitorEvent{ID: 1, Competitor: 1, Time: parse("09:00:00.000")})
+		require.NoError(t, err)
+		_, err = engine.Apply(model.CompetitorEvent{ID: 2, Competitor: 1, Time: parse("09:10:00.000"), ExtraParams: "09:30:00.000"})
+		require.NoError(t, err)
+
+		assert.Equal(t, []model.CompetitorEvent{
+			{ID: model.EventDisqualified, Competitor: 1, Time: parse("09:31:00.000")},
+		}, engine.Finish())
+	})
+
+	t.Run("InvalidStartTime", func(t *testing.T) {
+		engine := race.NewEngine(config, "15:04:05.000", 5)
+
+		_, err := engine.Apply(model.CompetitorEvent{ID: 2, Competitor: 1, Time: baseTime, ExtraParams: "soon"})
+		assert.Error(t, err)
+	})
+
+	t.Run("UnknownCompetitor", func(t *testing.T) {
+		engine := race.NewEngine(config, "15:04:05.000", 5)
+
+		_, ok := engine.Report(42)
+		assert.False(t, ok)
+	})
+
+	t.Run("GapsAndSplits", func(t *testing.T) {
+		config := config
+		config.Laps = 2
+		config.FiringLines = 0
+		engine := race.NewEngine(config, "15:04:05.000", 5)
+
+		at := func(seconds int) time.Time {
+			return baseTime.Add(time.Duration(seconds) * time.Second)
+		}
+
+		// Competitor 3 leads after the first lap, 2 wins, 1 gives up on the second lap.
+		laps := map[int][]int{1: {130, 0}, 2: {120, 240}, 3: {110, 250}}
+		var events []model.CompetitorEvent
+		for id := 1; id <= 3; id++ {
+			events = append(events,
+				model.CompetitorEvent{ID: 1, Competitor: id, Time: at(0)},
+				model.CompetitorEvent{ID: 2, Competitor: id, Time: at(1), ExtraParams: "10:00:30.000"},
+			)
+		}
+		for id := 1; id <= 3; id++ {
+			events = append(events, model.CompetitorEvent{ID: 4, Competitor: id, Time: at(30)})
+		}
+		events = append(events,
+			model.CompetitorEvent{ID: 10, Competitor: 3, Time: at(30 + laps[3][0])},
+			model.CompetitorEvent{ID: 10, Competitor: 2, Time: at(30 + laps[2][0])},
+			model.CompetitorEvent{ID: 10, Competitor: 1, Time: at(30 + laps[1][0])},
+			model.CompetitorEvent{ID: 11, Competitor: 1, Time: at(200), ExtraParams: "tired"},
+			model.CompetitorEvent{ID: 10, Competitor: 2, Time: at(30 + laps[2][1])},
+			model.CompetitorEvent{ID: 10, Competitor: 3, Time: at(30 + laps[3][1])},
+		)
+		for _, event := range events {
+			_, err := engine.Apply(event)
+			require.NoError(t, err)
+		}
+		engine.Finish()
+
+		reports := engine.Reports()
+		require.Len(t, reports, 3)
+
+		assert.Equal(t, 2, reports[0].CompetitorID)
+		assert.Equal(t, time.Duration(0), reports[0].GapToLeader)
+		assert.Equal(t, time.Duration(0), reports[0].GapToAhead)
+		assert.Equal(t, 3, reports[1].CompetitorID)
+		assert.Equal(t, 10*time.Second, reports[1].GapToLeader)
+		assert.Equal(t, 10*time.Second, reports[1].GapToAhead)
+		assert.Equal(t, 1, reports[2].CompetitorID)
+		assert.Equal(t, time.Duration(0), reports[2].GapToLeader, "no gap without a result")
+
+		type split struct {
+			split time.Duration
+			rank  int
+		}
+		splitsOf := func(report model.CompetitorReport) []split {
+			var splits []split
+			for _, lap := range report.Laps {
+				splits = append(splits, split{lap.Split, lap.SplitRank})
+			}
+			return splits
+		}
+
+		assert.Equal(t, []split{{120 * time.Second, 2}, {240 * time.Second, 1}}, splitsOf(reports[0]))
+		assert.Equal(t, []split{{110 * time.Second, 1}, {250 * time.Second, 2}}, splitsOf(reports[1]))
+		assert.Equal(t, []split{{130 * time.Second, 3}}, splitsOf(reports[2]))
+	})
+
+	t.Run("JuryDecisions", func(t *testing.T) {
+		config := config
+		config.Laps = 2
+		config.FiringLines = 0
+		engine := race.NewEngine(config, "15:04:05.000", 5)
+
+		at := func(seconds int) time.Time {
+			return baseTime.Add(time.Duration(seconds) * time.Second)
+		}
+
+		var events []model.CompetitorEvent
+		for id := 1; id <= 4; id++ {
+			events = append(events,
+				model.CompetitorEvent{ID: 1, Competitor: id, Time: at(0)},
+				model.CompetitorEvent{ID: 2, Competitor: id, Time: at(1), ExtraParams: "10:00:30.000"},
+			)
+		}
+		for id := 1; id <= 4; id++ {
+			events = append(events, model.CompetitorEvent{ID: 4, Competitor: id, Time: at(30)})
+		}
+		events = append(events,
+			model.CompetitorEvent{ID: 10, Competitor: 1, Time: at(100)},
+			model.CompetitorEvent{ID: 10, Competitor: 2, Time: at(110)},
+			model.CompetitorEvent{ID: 10, Competitor: 3, Time: at(120)},
+			model.CompetitorEvent{ID: 13, Competitor: 4, Time: at(150)},
+			model.CompetitorEvent{ID: 10, Competitor: 1, Time: at(170)},
+			model.CompetitorEvent{ID: 10, Competitor: 2, Time: at(190)},
+			model.CompetitorEvent{ID: 11, Competitor: 3, Time: at(200), ExtraParams: "broken ski"},
+			model.CompetitorEvent{ID: 12, Competitor: 1, Time: at(300), ExtraParams: "missed 2 penalty loops"},
+		)
+		for _, event := range events {
+			_, err := engine.Apply(event)
+			require.NoError(t, err)
+		}
+		engine.Finish()
+
+		type status struct {
+			id     int
+			status model.Status
+			reason string
+			place  int
+		}
+
+		var actual []status
+		for _, report := range engine.Reports() {
+			actual = append(actual, status{report.CompetitorID, report.Status, report.Reason, report.Place})
+		}
+
+		assert.Equal(t, []status{
+			{2, model.CompetitorStarted, "", 1},
+			{4, model.CompetitorLapped, "", 0},
+			{3, model.CompetitorNotFinished, "broken ski", 0},
+			{1, model.CompetitorDisqualified, "missed 2 penalty loops", 0},
+		}, actual)
+		assert.Equal(t, "[10:05:00.000] The competitor(1) is disqualified by the jury: missed 2 penalty loops", engine.LogLine(events[len(events)-1]))
+	})
+}
@@ -0,0 +1,127 @@
+package _test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Maksim646/sunny_5_skiers/internal/race"
+	"github.com/Maksim646/sunny_5_skiers/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRanking(t *testing.T) {
+	baseTime := time.Date(2025, time.May, 6, 10, 0, 0, 0, time.UTC)
+	config := model.Config{
+		Laps:              2,
+		LapLen:            3000,
+		PenaltyLen:        150,
+		FiringLines:       0,
+		Start:             baseTime,
+		StartDelta:        1 * time.Minute,
+		RankingResolution: 100 * time.Millisecond,
+		Roster: model.Roster{
+			1: {ID: 1, Bib: 5},
+			2: {ID: 2, Bib: 4},
+			3: {ID: 3, Bib: 3},
+			4: {ID: 4, Bib: 2},
+			5: {ID: 5, Bib: 1},
+		},
+	}
+
+	at := func(d time.Duration) time.Time {
+		return baseTime.Add(30*time.Second + d)
+	}
+
+	register := func(ids ...int) []model.CompetitorEvent {
+		var events []model.CompetitorEvent
+		for _, id := range ids {
+			events = append(events,
+				model.CompetitorEvent{ID: 1, Competitor: id, Time: baseTime},
+				model.CompetitorEvent{ID: 2, Competitor: id, Time: baseTime, ExtraParams: "10:00:30.000"},
+			)
+		}
+		return events
+	}
+
+	start := func(ids ...int) []model.CompetitorEvent {
+		var events []model.CompetitorEvent
+		for _, id := range ids {
+			events = append(events, model.CompetitorEvent{ID: 4, Competitor: id, Time: at(0)})
+		}
+		return events
+	}
+
+	lap := func(id int, d time.Duration) model.CompetitorEvent {
+		return model.CompetitorEvent{ID: 10, Competitor: id, Time: at(d)}
+	}
+
+	type place struct {
+		id    int
+		place int
+		gap   time.Duration
+	}
+
+	standings := func(reports []model.CompetitorReport) []place {
+		var places []place
+		for _, report := range reports {
+			places = append(places, place{report.CompetitorID, report.Place, report.GapToLeader})
+		}
+		return places
+	}
+
+	t.Run("SharedPlaces", func(t *testing.T) {
+		engine := race.NewEngine(config, "15:04:05.000", 5)
+
+		events := append(register(1, 2, 3, 4), start(1, 2, 3, 4)...)
+		events = append(events,
+			lap(1, 2*time.Minute), lap(2, 2*time.Minute), lap(3, 2*time.Minute), lap(4, 2*time.Minute),
+			lap(3, 5*time.Minute+40*time.Millisecond),
+			lap(1, 5*time.Minute+90*time.Millisecond),
+			lap(2, 5*time.Minute+150*time.Millisecond),
+			lap(4, 5*time.Minute+150*time.Millisecond),
+		)
+		applyEvents(t, engine, events)
+
+		assert.Equal(t, []place{
+			{3, 1, 0},
+			{1, 1, 0},
+			{4, 3, 100 * time.Millisecond},
+			{2, 3, 100 * time.Millisecond},
+		}, standings(engine.Reports()), "equal tenths share a place and are ordered by bib")
+	})
+
+	t.Run("NonFinishers", func(t *testing.T) {
+		engine := race.NewEngine(config, "15:04:05.000", 5)
+
+		events := append(register(1, 2, 3, 4, 5), start(1, 2, 3)...)
+		events = append(events,
+			lap(1, 2*time.Minute),
+			lap(2, 2*time.Minute+500*time.Millisecond),
+			lap(3, 3*time.Minute),
+			model.CompetitorEvent{ID: 11, Competitor: 3, Time: at(3 * time.Minute), ExtraParams: "broken pole"},
+			lap(2, 5*time.Minute),
+		)
+		applyEvents(t, engine, events)
+		engine.Finish()
+
+		assert.Equal(t, []place{
+			{2, 1, 0},
+			{1, 0, 0},
+			{3, 0, 0},
+			{5, 0, 0},
+			{4, 0, 0},
+		}, standings(engine.Reports()), "DNF by laps and last split, then DNS by bib")
+	})
+
+	t.Run("Deterministic", func(t *testing.T) {
+		first := race.NewEngine(config, "15:04:05.000", 5)
+		applyEvents(t, first, append(register(5, 4, 3, 2, 1), start(1)...))
+		first.Finish()
+
+		second := race.NewEngine(config, "15:04:05.000", 5)
+		applyEvents(t, second, append(register(1, 2, 3, 4, 5), start(1)...))
+		second.Finish()
+
+		assert.Equal(t, first.Reports(), second.Reports())
+	})
+}
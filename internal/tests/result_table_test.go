@@ -0,0 +1,190 @@
+package _test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Maksim646/sunny_5_skiers/internal/controller"
+	"github.com/Maksim646/sunny_5_skiers/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseResultTable(t *testing.T) {
+	t.Run("RoundTrip", func(t *testing.T) {
+		tests := []struct {
+			path   string
+			config model.Config
+		}{
+			{"test_process_events/test_result_table_expected.txt", model.Config{Laps: 2, FiringLines: 2}},
+			{"test_process_events/test_result_table_not_finished_expected.txt", model.Config{Laps: 2, FiringLines: 2}},
+			{"test_process_events/test_result_table_not_started_expected.txt", model.Config{Laps: 2, FiringLines: 2}},
+			{"test_process_events/test_result_table_categories_expected.txt", model.Config{Laps: 1}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.path, func(t *testing.T) {
+				table, err := os.ReadFile(tt.path)
+				require.NoError(t, err)
+
+				reports, err := controller.ParseResultTable(bytes.NewReader(table))
+				require.NoError(t, err)
+
+				var written bytes.Buffer
+				require.NoError(t, controller.WriteResultTableText(&written, reports, tt.config, controller.DefaultReportTableTimeFormat))
+				assert.Equal(t, string(table), written.String())
+			})
+		}
+	})
+
+	t.Run("Fields", func(t *testing.T) {
+		file, err := os.Open("test_process_events/test_result_table_categories_expected.txt")
+		require.NoError(t, err)
+		defer file.Close()
+
+		reports, err := controller.ParseResultTable(file)
+		require.NoError(t, err)
+		require.Len(t, reports, 4)
+
+		ivan := reports[2]
+		assert.Equal(t, 1, ivan.CompetitorID)
+		assert.Equal(t, "Ivan Petrov", ivan.Athlete.Name)
+		assert.Equal(t, model.CompetitorStarted, ivan.Status)
+		assert.Equal(t, 5*time.Minute, ivan.TotalTime)
+		assert.Equal(t, 30*time.Second, ivan.GapToLeader)
+		assert.Equal(t, 3, ivan.Place)
+		assert.Equal(t, "Men", ivan.Category)
+		assert.Equal(t, 2, ivan.CategoryPlace)
+		assert.Equal(t, []model.LapInfo{{Time: 5 * time.Minute, Speed: 10}}, ivan.Laps)
+
+		notFinished := reports[3]
+		assert.Equal(t, model.CompetitorNotFinished, notFinished.Status)
+		assert.Equal(t, 0, notFinished.Place)
+		assert.Equal(t, "Women", notFinished.Category)
+		assert.Empty(t, notFinished.Laps)
+	})
+
+	t.Run("MarkersAndSharedPlaces", func(t *testing.T) {
+		table := "[00:05:00.000] 1 [{00:05:00.000, 10.000}] {,} 0/0 []\n" +
+			"[00:05:00.000] 2 [{00:05:00.000, 10.000}] {,} 0/0 []\n" +
+			"[00:05:10.000] 3 +00:00:10.000 [{00:05:10.000, 9.677}] {00:00:30.000, 5.000} 4/5 [{1, 11101, 00:00:40.000}]\n" +
+			"[Running] 4 [{,}] {,} 0/0 []\n" +
+			"[Lapped] 5 [{,}] {,} 0/0 []\n" +
+			"[Disqualified] 6 [{,}] {,} 0/0 []\n"
+
+		reports, err := controller.ParseResultTable(strings.NewReader(table))
+		require.NoError(t, err)
+		require.Len(t, reports, 6)
+
+		var places []int
+		var statuses []model.Status
+		for _, report := range reports {
+			places = append(places, report.Place)
+			statuses = append(statuses, report.Status)
+		}
+		assert.Equal(t, []int{1, 1, 3, 0, 0, 0}, places)
+		assert.Equal(t, []model.Status{
+			model.CompetitorStarted, model.CompetitorStarted, model.CompetitorStarted,
+			model.CompetitorRunning, model.CompetitorLapped, model.CompetitorDisqualified,
+		}, statuses)
+
+		third := reports[2]
+		assert.Equal(t, 30*time.Second, third.PenaltyTime)
+		assert.Zero(t, third.PenaltyLoopsRun, "the table does not keep the loops run")
+		assert.Equal(t, []model.FiringRangeVisit{
+			{FiringRange: 1, Targets: 5, HitTargets: []int{1, 2, 3, 5}, Misses: 1, Time: 40 * time.Second},
+		}, third.FiringRanges)
+
+		var written bytes.Buffer
+		require.NoError(t, controller.WriteResultTableText(&written, reports, model.Config{Laps: 1}, controller.DefaultReportTableTimeFormat))
+		assert.Equal(t, table, written.String())
+	})
+
+	t.Run("MissedPenaltyLoops", func(t *testing.T) {
+		reports := []model.CompetitorReport{
+			{CompetitorID: 1, Status: model.CompetitorStarted, TotalTime: 5 * time.Minute,
+				Laps: []model.LapInfo{{Time: 5 * time.Minute, Speed: 10}}, PenaltyLoopsRun: 2, MissedPenaltyLoops: 1,
+				PenaltyTime: 30 * time.Second, PenaltySpeed: 10, Hits: 2, Shots: 5},
+			{CompetitorID: 2, Status: model.CompetitorStarted, TotalTime: 6 * time.Minute,
+				Laps: []model.LapInfo{{Time: 6 * time.Minute, Speed: 8.333}}, MissedPenaltyLoops: 2, Hits: 3, Shots: 5},
+		}
+
+		var written bytes.Buffer
+		require.NoError(t, controller.WriteResultTableText(&written, reports, model.Config{Laps: 1}, controller.DefaultReportTableTimeFormat))
+		assert.Equal(t, "[00:05:00.000] 1 [{00:05:00.000, 10.000}] {00:00:30.000, 10.000} missed:1 2/5 []\n"+
+			"[00:06:00.000] 2 [{00:06:00.000, 8.333}] {,} missed:2 3/5 []\n", written.String())
+
+		parsed, err := controller.ParseResultTable(&written)
+		require.NoError(t, err)
+		require.Len(t, parsed, 2)
+		assert.Equal(t, 1, parsed[0].MissedPenaltyLoops)
+		assert.Equal(t, 2, parsed[1].MissedPenaltyLoops)
+	})
+
+	t.Run("NotKept", func(t *testing.T) {
+		reports := []model.CompetitorReport{
+			{CompetitorID: 1, Status: model.CompetitorStarted, TotalTime: 6 * time.Minute, TimePenalty: time.Minute,
+				Laps: []model.LapInfo{{Time: 5 * time.Minute, Speed: 10}}, Hits: 4, Shots: 5},
+			{CompetitorID: 2, Status: model.CompetitorDisqualified, Reason: "false start"},
+		}
+
+		var written bytes.Buffer
+		require.NoError(t, controller.WriteResultTableText(&written, reports, model.Config{Laps: 1}, controller.DefaultReportTableTimeFormat))
+
+		parsed, err := controller.ParseResultTable(&written)
+		require.NoError(t, err)
+		require.Len(t, parsed, 2)
+		assert.Equal(t, 6*time.Minute, parsed[0].TotalTime)
+		assert.Zero(t, parsed[0].TimePenalty)
+		assert.Equal(t, model.CompetitorDisqualified, parsed[1].Status)
+		assert.Empty(t, parsed[1].Reason)
+	})
+
+	t.Run("OtherTimeFormat", func(t *testing.T) {
+		table := "[05:00.0] 1 [{05:00.0, 10.000}] {,} 0/0 []\n" +
+			"[1:05:00.5] 2 +1:00:00.5 [{1:05:00.5, 0.769}] {,} 0/0 []\n"
+
+		reports, err := controller.ReadResultTable(strings.NewReader(table), "table", "[h:]mm:ss.f")
+		require.NoError(t, err)
+		assert.Equal(t, time.Hour+5*time.Minute+500*time.Millisecond, reports[1].TotalTime)
+
+		var written bytes.Buffer
+		require.NoError(t, controller.WriteResultTableText(&written, reports, model.Config{Laps: 1}, "[h:]mm:ss.f"))
+		assert.Equal(t, table, written.String())
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			content string
+			line    int
+			reason  string
+		}{
+			{"NoResult", "1 [] {,} 0/0 []\n", 1, "expected [result]"},
+			{"UnknownMarker", "[Resting] 1 [] {,} 0/0 []\n", 1, `invalid result "Resting"`},
+			{"InvalidID", "[NotStarted] one [] {,} 0/0 []\n", 1, "invalid competitor ID"},
+			{"InvalidLap", "[00:05:00.000] 1 [{00:05:00.000}] {,} 0/0 []\n", 1, "lap expects {time, speed}"},
+			{"NoHits", "[NotStarted] 1 [] {,} []\n", 1, "expected hits/shots"},
+			{"InvalidMissed", "[NotStarted] 1 [] {,} missed:x 0/0 []\n", 1, `invalid missed penalty loops "missed:x"`},
+			{"InvalidMask", "[NotStarted] 1 [] {,} 0/0 [{1, 1x, 00:00:10.000}]\n", 1, `invalid hit mask "1x"`},
+			{"TrailingText", "[NotStarted] 1 [] {,} 0/0 [] extra\n", 1, `unexpected "extra"`},
+			{"DuplicateID", "[NotStarted] 1 [] {,} 0/0 []\n\n[NotStarted] 1 [] {,} 0/0 []\n", 3, "duplicate competitor ID 1"},
+			{"UnknownCategoryCompetitor", "# Overall\n[NotStarted] 1 [] {,} 0/0 []\n\n# Men\n[NotStarted] 2 [] {,} 0/0 []\n", 5, "competitor(2) is not in the overall section"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				reports, err := controller.ParseResultTable(strings.NewReader(tt.content))
+				assert.Nil(t, reports)
+
+				var parseErr *controller.ParseError
+				require.ErrorAs(t, err, &parseErr)
+				assert.Equal(t, tt.line, parseErr.Line)
+				assert.Equal(t, tt.reason, parseErr.Reason)
+			})
+		}
+	})
+}
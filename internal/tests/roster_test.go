@@ -0,0 +1,117 @@
+package _test
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Maksim646/sunny_5_skiers/internal/controller"
+	"github.com/Maksim646/sunny_5_skiers/internal/output"
+	"github.com/Maksim646/sunny_5_skiers/internal/race"
+	"github.com/Maksim646/sunny_5_skiers/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRoster(t *testing.T) {
+	expected := model.Roster{
+		1: {ID: 1, Bib: 11, Name: "Ivan Petrov", Nation: "RUS", Club: "Dynamo", Category: "Men", Gender: "M"},
+		2: {ID: 2, Bib: 12, Name: "Anna Berg, Jr.", Nation: "NOR", Category: "Women", Gender: "F"},
+	}
+
+	t.Run("CSV", func(t *testing.T) {
+		roster, err := controller.ParseRoster("test_roster/test_roster.csv")
+		require.NoError(t, err)
+		assert.Equal(t, expected, roster)
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		roster, err := controller.ParseRoster("test_roster/test_roster.json")
+		require.NoError(t, err)
+		assert.Equal(t, expected, roster)
+	})
+
+	tests := []struct {
+		name   string
+		path   string
+		line   int
+		reason string
+	}{
+		{name: "Duplicate", path: "test_roster/test_roster_duplicate.csv", line: 3, reason: "duplicate competitor ID 1"},
+		{name: "UnknownColumn", path: "test_roster/test_roster_unknown_column.csv", line: 1, reason: `unknown roster column "shoesize"`},
+		{name: "OverallCategory", path: "test_roster/test_roster_overall_category.csv", line: 2, reason: `category "Overall" is reserved for the overall ranking`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := controller.ParseRoster(tt.path)
+
+			var parseErr *controller.ParseError
+			require.True(t, errors.As(err, &parseErr), "expected *ParseError, got %v", err)
+			assert.Equal(t, tt.path, parseErr.File)
+			assert.Equal(t, tt.line, parseErr.Line)
+			assert.Equal(t, tt.reason, parseErr.Reason)
+		})
+	}
+}
+
+func TestRoster(t *testing.T) {
+	baseTime := time.Date(2025, time.May, 6, 10, 0, 0, 0, time.UTC)
+	config := model.Config{
+		Laps:        1,
+		LapLen:      3500,
+		PenaltyLen:  150,
+		FiringLines: 1,
+		Start:       baseTime,
+		StartDelta:  1 * time.Minute,
+		Roster: model.Roster{
+			1: {ID: 1, Bib: 11, Name: "Ivan Petrov", Nation: "RUS"},
+		},
+	}
+
+	events := []model.CompetitorEvent{
+		{ID: 1, Competitor: 1, Time: baseTime},
+		{ID: 1, Competitor: 2, Time: baseTime},
+	}
+
+	t.Run("LogLine", func(t *testing.T) {
+		engine := race.NewEngine(config, "15:04:05.000", 5)
+		assert.Equal(t, "[10:00:00.000] The competitor(1, Ivan Petrov) registered", engine.LogLine(events[0]))
+		assert.Equal(t, "[10:00:00.000] The competitor(2) registered", engine.LogLine(events[1]))
+	})
+
+	t.Run("ResultTable", func(t *testing.T) {
+		actualPath := "test_roster/test_result_table_actual.txt"
+		defer os.Remove(actualPath)
+
+		engine := race.NewEngine(config, "15:04:05.000", 5)
+		applyEvents(t, engine, events)
+		engine.Finish()
+
+		report, ok := engine.Report(1)
+		require.True(t, ok)
+		assert.Equal(t, "Ivan Petrov", report.Athlete.Name)
+
+		err := controller.GenerateResultingTable(engine, actualPath, output.FormatText, "hh:mm:ss.fff")
+		require.NoError(t, err)
+
+		actualContent, err := os.ReadFile(actualPath)
+		require.NoError(t, err)
+		assert.Equal(t,
+			"[NotStarted] 1 \"Ivan Petrov\" [{,}] {,} 0/0 [{,}]\n"+
+				"[NotStarted] 2 [{,}] {,} 0/0 [{,}]\n",
+			string(actualContent))
+	})
+
+	t.Run("UnknownCompetitor", func(t *testing.T) {
+		err := controller.ValidateSequence(events, config, "15:04:05.000", 5, true)
+
+		var sequenceErr *controller.SequenceError
+		require.True(t, errors.As(err, &sequenceErr), "expected *SequenceError, got %v", err)
+		assert.Equal(t, 2, sequenceErr.Event.Competitor)
+		assert.Equal(t, "competitor is not on the roster", sequenceErr.Reason)
+
+		assert.NoError(t, controller.ValidateSequence(events, config, "15:04:05.000", 5, false), "lenient mode must only warn")
+	})
+}
@@ -0,0 +1,139 @@
+package _test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Maksim646/sunny_5_skiers/internal/controller"
+	"github.com/Maksim646/sunny_5_skiers/internal/race"
+	"github.com/Maksim646/sunny_5_skiers/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRules(t *testing.T) {
+	baseTime := time.Date(2025, time.May, 6, 10, 0, 0, 0, time.UTC)
+	config := model.Config{
+		Laps:        1,
+		LapLen:      3000,
+		PenaltyLen:  150,
+		FiringLines: 1,
+		Start:       baseTime,
+		StartDelta:  1 * time.Minute,
+	}
+
+	shootOnce := func(at time.Time) []model.CompetitorEvent {
+		return []model.CompetitorEvent{
+			{ID: 5, Competitor: 1, Time: at, ExtraParams: "1"},
+			{ID: 6, Competitor: 1, Time: at.Add(2 * time.Second), ExtraParams: "1"},
+			{ID: 6, Competitor: 1, Time: at.Add(4 * time.Second), ExtraParams: "2"},
+			{ID: 6, Competitor: 1, Time: at.Add(6 * time.Second), ExtraParams: "3"},
+			{ID: 7, Competitor: 1, Time: at.Add(10 * time.Second)},
+		}
+	}
+
+	apply := func(t *testing.T, engine *race.Engine, events []model.CompetitorEvent) {
+		for _, event := range events {
+			_, err := engine.Apply(event)
+			require.NoError(t, err)
+		}
+	}
+
+	t.Run("ShootingOrder", func(t *testing.T) {
+		sprint := race.NewRules(model.Config{Format: model.FormatSprint})
+		pursuit := race.NewRules(model.Config{Format: model.FormatPursuit})
+
+		var sprintOrder, pursuitOrder []string
+		for visit := 1; visit <= 4; visit++ {
+			sprintOrder = append(sprintOrder, sprint.Position(visit))
+			pursuitOrder = append(pursuitOrder, pursuit.Position(visit))
+		}
+
+		p, s := model.PositionProne, model.PositionStanding
+		assert.Equal(t, []string{p, s, p, s}, sprintOrder)
+		assert.Equal(t, []string{p, p, s, s}, pursuitOrder)
+	})
+
+	t.Run("IndividualTimePenalty", func(t *testing.T) {
+		individual := config
+		individual.Format = model.FormatIndividual
+		engine := race.NewEngine(individual, "15:04:05.000", 5)
+
+		apply(t, engine, []model.CompetitorEvent{
+			{ID: 1, Competitor: 1, Time: baseTime},
+			{ID: 2, Competitor: 1, Time: baseTime.Add(10 * time.Second), ExtraParams: "10:00:30.000"},
+			{ID: 3, Competitor: 1, Time: baseTime.Add(20 * time.Second)},
+			{ID: 4, Competitor: 1, Time: baseTime.Add(30 * time.Second)},
+		})
+		apply(t, engine, shootOnce(baseTime.Add(60*time.Second)))
+		apply(t, engine, []model.CompetitorEvent{
+			{ID: 10, Competitor: 1, Time: baseTime.Add(330 * time.Second)},
+		})
+
+		report, ok := engine.Report(1)
+		require.True(t, ok)
+		assert.Equal(t, 0, report.PenaltyLoops)
+		assert.Equal(t, 0, report.MissedPenaltyLoops)
+		assert.Equal(t, 2*time.Minute, report.TimePenalty)
+		assert.Equal(t, 7*time.Minute, report.TotalTime)
+	})
+
+	t.Run("IndividualIgnoresPenaltyLaps", func(t *testing.T) {
+		individual := config
+		individual.Format = model.FormatIndividual
+		engine := race.NewEngine(individual, "15:04:05.000", 5)
+
+		events := []model.CompetitorEvent{
+			{ID: 1, Competitor: 1, Time: baseTime},
+			{ID: 2, Competitor: 1, Time: baseTime.Add(10 * time.Second), ExtraParams: "10:00:30.000"},
+			{ID: 3, Competitor: 1, Time: baseTime.Add(20 * time.Second)},
+			{ID: 4, Competitor: 1, Time: baseTime.Add(30 * time.Second)},
+		}
+		events = append(events, shootOnce(baseTime.Add(60*time.Second))...)
+		events = append(events,
+			model.CompetitorEvent{ID: 8, Competitor: 1, Time: baseTime.Add(80 * time.Second)},
+			model.CompetitorEvent{ID: 9, Competitor: 1, Time: baseTime.Add(110 * time.Second)},
+			model.CompetitorEvent{ID: 10, Competitor: 1, Time: baseTime.Add(330 * time.Second)},
+		)
+		apply(t, engine, events)
+
+		report, ok := engine.Report(1)
+		require.True(t, ok)
+		assert.Equal(t, 0, report.PenaltyLoopsRun)
+		assert.Equal(t, time.Duration(0), report.PenaltyTime)
+		assert.Empty(t, report.PenaltyLaps)
+		assert.Equal(t, 2*time.Minute, report.TimePenalty)
+
+		err := controller.ValidateSequence(events, individual, "15:04:05.000", 5, true)
+		var sequenceErr *controller.SequenceError
+		require.ErrorAs(t, err, &sequenceErr)
+		assert.Equal(t, model.EventPenaltyLapStart, sequenceErr.Event.ID)
+		assert.Equal(t, "penalty laps in a format with a time penalty", sequenceErr.Reason)
+	})
+
+	t.Run("MassStart", func(t *testing.T) {
+		massStart := config
+		massStart.Format = model.FormatMassStart
+		engine := race.NewEngine(massStart, "15:04:05.000", 5)
+
+		apply(t, engine, []model.CompetitorEvent{
+			{ID: 1, Competitor: 1, Time: baseTime.Add(-10 * time.Minute)},
+			{ID: 1, Competitor: 2, Time: baseTime.Add(-9 * time.Minute)},
+			{ID: 4, Competitor: 1, Time: baseTime.Add(2 * time.Second)},
+		})
+
+		lap := model.CompetitorEvent{ID: 10, Competitor: 1, Time: baseTime.Add(300 * time.Second)}
+		outputEvents, err := engine.Apply(lap)
+		require.NoError(t, err)
+		assert.Equal(t, []model.CompetitorEvent{
+			{ID: model.EventDisqualified, Competitor: 2, Time: baseTime.Add(time.Minute)},
+			lap,
+			{ID: model.EventFinished, Competitor: 1, Time: lap.Time},
+		}, outputEvents)
+
+		report, ok := engine.Report(1)
+		require.True(t, ok)
+		assert.Equal(t, 5*time.Minute, report.TotalTime)
+		assert.Equal(t, 2*time.Second, report.StartLag)
+	})
+}
@@ -0,0 +1,163 @@
+package _test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Maksim646/sunny_5_skiers/internal/controller"
+	"github.com/Maksim646/sunny_5_skiers/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateSequence(t *testing.T) {
+	baseTime := time.Date(2025, time.May, 6, 10, 0, 0, 0, time.UTC)
+	config := model.Config{
+		Laps:        1,
+		LapLen:      3500,
+		PenaltyLen:  150,
+		FiringLines: 1,
+		Start:       baseTime,
+		StartDelta:  1 * time.Minute,
+	}
+
+	at := func(seconds int) time.Time {
+		return baseTime.Add(time.Duration(seconds) * time.Second)
+	}
+
+	started := []model.CompetitorEvent{
+		{ID: 1, Competitor: 1, Time: at(0)},
+		{ID: 2, Competitor: 1, Time: at(1), ExtraParams: "10:00:30.000"},
+		{ID: 3, Competitor: 1, Time: at(2)},
+		{ID: 4, Competitor: 1, Time: at(30)},
+	}
+
+	withStarted := func(events ...model.CompetitorEvent) []model.CompetitorEvent {
+		return append(append([]model.CompetitorEvent(nil), started...), events...)
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		events := withStarted(
+			model.CompetitorEvent{ID: 5, Competitor: 1, Time: at(40), ExtraParams: "1"},
+			model.CompetitorEvent{ID: 6, Competitor: 1, Time: at(41), ExtraParams: "1"},
+			model.CompetitorEvent{ID: 6, Competitor: 1, Time: at(42), ExtraParams: "5"},
+			model.CompetitorEvent{ID: 7, Competitor: 1, Time: at(43)},
+			model.CompetitorEvent{ID: 8, Competitor: 1, Time: at(44)},
+			model.CompetitorEvent{ID: 9, Competitor: 1, Time: at(45)},
+			model.CompetitorEvent{ID: 10, Competitor: 1, Time: at(46)},
+		)
+
+		assert.NoError(t, controller.ValidateSequence(events, config, "15:04:05.000", 5, true))
+	})
+
+	t.Run("ErrorInTimeFormat", func(t *testing.T) {
+		events := []model.CompetitorEvent{
+			{ID: 1, Competitor: 1, Time: at(10)},
+			{ID: 1, Competitor: 2, Time: at(5)},
+		}
+
+		err := controller.ValidateSequence(events, config, "15:04:05", 5, true)
+		assert.EqualError(t, err, "event 1 for competitor(2) at 10:00:05: event goes back in time, previous event at 10:00:10")
+	})
+
+	t.Run("DisqualifiedAfterFinish", func(t *testing.T) {
+		events := withStarted(
+			model.CompetitorEvent{ID: 10, Competitor: 1, Time: at(40)},
+			model.CompetitorEvent{ID: 12, Competitor: 1, Time: at(50), ExtraParams: "missed 2 penalty loops"},
+		)
+
+		assert.NoError(t, controller.ValidateSequence(events, config, "15:04:05.000", 5, true))
+	})
+
+	tests := []struct {
+		name   string
+		events []model.CompetitorEvent
+		reason string
+	}{
+		{
+			name: "BackInTime",
+			events: withStarted(
+				model.CompetitorEvent{ID: 5, Competitor: 1, Time: at(20), ExtraParams: "1"},
+			),
+			reason: "event goes back in time, previous event at 10:00:30.000",
+		},
+		{
+			name: "NotRegistered",
+			events: []model.CompetitorEvent{
+				{ID: 2, Competitor: 1, Time: at(0), ExtraParams: "10:00:30.000"},
+			},
+			reason: "competitor is not registered",
+		},
+		{
+			name: "StartBeforeDraw",
+			events: []model.CompetitorEvent{
+				{ID: 1, Competitor: 1, Time: at(0)},
+				{ID: 4, Competitor: 1, Time: at(1)},
+			},
+			reason: "started before the start time draw",
+		},
+		{
+			name:   "HitOutsideFiringRange",
+			events: withStarted(model.CompetitorEvent{ID: 6, Competitor: 1, Time: at(40), ExtraParams: "1"}),
+			reason: "target hit outside a firing range",
+		},
+		{
+			name: "TargetAboveFireLine",
+			events: withStarted(
+				model.CompetitorEvent{ID: 5, Competitor: 1, Time: at(40), ExtraParams: "1"},
+				model.CompetitorEvent{ID: 6, Competitor: 1, Time: at(41), ExtraParams: "6"},
+			),
+			reason: "target 6 is above the 5 targets in the fire line",
+		},
+		{
+			name:   "PenaltyExitWithoutEntry",
+			events: withStarted(model.CompetitorEvent{ID: 9, Competitor: 1, Time: at(40)}),
+			reason: "left the penalty laps without entering them",
+		},
+		{
+			name: "LapOnFiringRange",
+			events: withStarted(
+				model.CompetitorEvent{ID: 5, Competitor: 1, Time: at(40), ExtraParams: "1"},
+				model.CompetitorEvent{ID: 10, Competitor: 1, Time: at(41)},
+			),
+			reason: "ended a lap while on a firing range",
+		},
+		{
+			name: "EventAfterFinish",
+			events: withStarted(
+				model.CompetitorEvent{ID: 10, Competitor: 1, Time: at(40)},
+				model.CompetitorEvent{ID: 10, Competitor: 1, Time: at(41)},
+			),
+			reason: "competitor has already left the race",
+		},
+		{
+			name: "LappedBeforeStart",
+			events: []model.CompetitorEvent{
+				{ID: 1, Competitor: 1, Time: at(0)},
+				{ID: 13, Competitor: 1, Time: at(1)},
+			},
+			reason: "lapped before the start",
+		},
+		{
+			name: "EventAfterDisqualification",
+			events: withStarted(
+				model.CompetitorEvent{ID: 12, Competitor: 1, Time: at(40), ExtraParams: "false start"},
+				model.CompetitorEvent{ID: 10, Competitor: 1, Time: at(41)},
+			),
+			reason: "competitor is disqualified",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := controller.ValidateSequence(tt.events, config, "15:04:05.000", 5, true)
+
+			var sequenceErr *controller.SequenceError
+			require.ErrorAs(t, err, &sequenceErr)
+			assert.Equal(t, tt.events[len(tt.events)-1], sequenceErr.Event)
+			assert.Equal(t, tt.reason, sequenceErr.Reason)
+
+			assert.NoError(t, controller.ValidateSequence(tt.events, config, "15:04:05.000", 5, false), "lenient mode must only warn")
+		})
+	}
+}
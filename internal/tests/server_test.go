@@ -0,0 +1,319 @@
+package _test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Maksim646/sunny_5_skiers/internal/controller"
+	"github.com/Maksim646/sunny_5_skiers/internal/output"
+	"github.com/Maksim646/sunny_5_skiers/internal/server"
+	"github.com/Maksim646/sunny_5_skiers/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer(t *testing.T) {
+	timeFormat := "15:04:05.000"
+	config := model.Config{
+		Laps:        1,
+		LapLen:      3000,
+		PenaltyLen:  150,
+		FiringLines: 1,
+		Format:      model.FormatSprint,
+		StartRaw:    "10:00:00.000",
+		DeltaRaw:    "00:01:00",
+		StartDelta:  time.Minute,
+	}
+
+	srv := server.New(config, timeFormat, 5, true)
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	post := func(t *testing.T, contentType string, body string) (int, string) {
+		resp, err := http.Post(ts.URL+"/events", contentType, strings.NewReader(body))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var payload json.RawMessage
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&payload))
+		return resp.StatusCode, string(payload)
+	}
+
+	get := func(t *testing.T, path string, v any) int {
+		resp, err := http.Get(ts.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(v))
+		return resp.StatusCode
+	}
+
+	t.Run("PostTextEvents", func(t *testing.T) {
+		status, body := post(t, "text/plain", "[09:00:00.000] 1 1\n[09:00:01.000] 1 2\n[09:10:00.000] 2 1 10:00:00.000\n")
+		assert.Equal(t, http.StatusOK, status)
+		assert.JSONEq(t, `[
+			"[09:00:00.000] The competitor(1) registered",
+			"[09:00:01.000] The competitor(2) registered",
+			"[09:10:00.000] The start time for the competitor(1) was set by a draw to 10:00:00.000"
+		]`, body)
+	})
+
+	t.Run("PostJSONEvents", func(t *testing.T) {
+		status, body := post(t, "application/json", `[
+			{"time": "09:59:00.000", "id": 3, "competitor": 1},
+			{"time": "10:00:00.500", "id": 4, "competitor": 1},
+			{"time": "10:05:00.000", "id": 10, "competitor": 1}
+		]`)
+		assert.Equal(t, http.StatusOK, status)
+		assert.JSONEq(t, `[
+			"[09:59:00.000] The competitor(1) is on the start line",
+			"[10:00:00.500] The competitor(1) has started",
+			"[10:05:00.000] The competitor(1) ended the main lap",
+			"[10:05:00.000] The competitor(1) has finished"
+		]`, body)
+	})
+
+	t.Run("PostInvalidEvent", func(t *testing.T) {
+		status, body := post(t, "text/plain", "[10:06:00.000] 6 1\n")
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.Contains(t, body, "event 6 requires a target number")
+	})
+
+	t.Run("PostOutOfSequence", func(t *testing.T) {
+		status, body := post(t, "application/json", `{"time": "10:06:00.000", "id": 9, "competitor": 2}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, status)
+		assert.Contains(t, body, "left the penalty laps without entering them")
+	})
+
+	t.Run("PostPartialBatch", func(t *testing.T) {
+		ts := httptest.NewServer(server.New(config, timeFormat, 5, true).Handler())
+		defer ts.Close()
+
+		resp, err := http.Post(ts.URL+"/events", "application/json", strings.NewReader(`[
+			{"time": "09:00:00.000", "id": 1, "competitor": 1},
+			{"time": "09:00:01.000", "id": 9, "competitor": 1}
+		]`))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var body json.RawMessage
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+		assert.JSONEq(t, `{
+			"error": "event 9 for competitor(1) at 09:00:01.000: left the penalty laps without entering them",
+			"applied": 1
+		}`, string(body))
+	})
+
+	t.Run("RejectedEventLeavesNoTrace", func(t *testing.T) {
+		srv := server.New(config, timeFormat, 5, true)
+		ts := httptest.NewServer(srv.Handler())
+		defer ts.Close()
+
+		post := func(body string) int {
+			resp, err := http.Post(ts.URL+"/events", "text/plain", strings.NewReader(body))
+			require.NoError(t, err)
+			resp.Body.Close()
+			return resp.StatusCode
+		}
+
+		assert.Equal(t, http.StatusOK, post("[09:00:00.000] 1 1\n"))
+		assert.Equal(t, http.StatusUnprocessableEntity, post("[09:01:00.000] 4 1\n"), "start before the draw")
+		assert.Equal(t, http.StatusOK, post("[09:02:00.000] 2 1 10:00:00.000\n"))
+		assert.Equal(t, http.StatusOK, post("[09:03:00.000] 3 1\n"))
+
+		// A rejected event the next day must not roll the race over midnight.
+		assert.Equal(t, http.StatusUnprocessableEntity, post("[23:00:00.000] 9 1\n"))
+		assert.Equal(t, http.StatusOK, post("[10:00:00.000] 4 1\n"))
+
+		resp, err := http.Get(ts.URL + "/competitors/1")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var competitor map[string]any
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&competitor))
+		assert.EqualValues(t, 0, competitor["startLagMs"])
+	})
+
+	t.Run("Competitor", func(t *testing.T) {
+		var competitor map[string]any
+		assert.Equal(t, http.StatusOK, get(t, "/competitors/1", &competitor))
+		assert.Equal(t, string(model.CompetitorStarted), competitor["status"])
+		assert.EqualValues(t, 300000, competitor["totalTimeMs"])
+		assert.EqualValues(t, 500, competitor["startLagMs"])
+
+		var notFound map[string]string
+		assert.Equal(t, http.StatusNotFound, get(t, "/competitors/42", &notFound))
+		assert.Equal(t, "competitor(42) not found", notFound["error"])
+
+		var badID map[string]string
+		assert.Equal(t, http.StatusBadRequest, get(t, "/competitors/first", &badID))
+	})
+
+	t.Run("CompetitorsAndResults", func(t *testing.T) {
+		var competitors []map[string]any
+		assert.Equal(t, http.StatusOK, get(t, "/competitors", &competitors))
+		require.Len(t, competitors, 2)
+		assert.EqualValues(t, 1, competitors[0]["competitorId"])
+		assert.EqualValues(t, 2, competitors[1]["competitorId"])
+
+		var results output.Results
+		assert.Equal(t, http.StatusOK, get(t, "/results", &results))
+		assert.Equal(t, output.SchemaVersion, results.SchemaVersion)
+		require.Len(t, results.Results, 2)
+		assert.Equal(t, 1, results.Results[0].CompetitorID)
+	})
+
+	t.Run("Log", func(t *testing.T) {
+		var log []string
+		assert.Equal(t, http.StatusOK, get(t, "/log", &log))
+		assert.Len(t, log, 7)
+	})
+
+	t.Run("Config", func(t *testing.T) {
+		var served map[string]any
+		assert.Equal(t, http.StatusOK, get(t, "/config", &served))
+		assert.Equal(t, "sprint", served["format"])
+		assert.Equal(t, "00:01:00", served["startDelta"])
+	})
+}
+
+func TestServerStream(t *testing.T) {
+	timeFormat := "15:04:05.000"
+	config := model.Config{
+		Laps:        1,
+		LapLen:      3000,
+		PenaltyLen:  150,
+		FiringLines: 1,
+		StartDelta:  time.Minute,
+	}
+
+	srv := server.New(config, timeFormat, 5, false)
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	apply := func(t *testing.T, lines ...string) {
+		for _, line := range lines {
+			event, err := controller.ParseEventLine(line, timeFormat)
+			require.NoError(t, err)
+			_, err = srv.Apply(event)
+			require.NoError(t, err)
+		}
+	}
+
+	type message struct {
+		id    string
+		event string
+		data  map[string]any
+	}
+
+	subscribe := func(t *testing.T, lastEventID string) (*bufio.Reader, func()) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/stream", nil)
+		require.NoError(t, err)
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		return bufio.NewReader(resp.Body), func() {
+			cancel()
+			resp.Body.Close()
+		}
+	}
+
+	next := func(t *testing.T, reader *bufio.Reader) message {
+		var msg message
+		for {
+			line, err := reader.ReadString('\n')
+			require.NoError(t, err)
+			line = strings.TrimSuffix(line, "\n")
+			switch {
+			case line == "":
+				return msg
+			case strings.HasPrefix(line, "id: "):
+				msg.id = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "event: "):
+				msg.event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &msg.data))
+			}
+		}
+	}
+
+	apply(t,
+		"[09:00:00.000] 1 1",
+		"[09:10:00.000] 2 1 10:00:00.000",
+		"[09:59:00.000] 3 1",
+		"[10:00:00.000] 4 1",
+	)
+
+	reader, closeStream := subscribe(t, "")
+	started := next(t, reader)
+	assert.Equal(t, "1", started.id)
+	assert.Equal(t, "started", started.event)
+	assert.Equal(t, "[10:00:00.000] The competitor(1) has started", started.data["line"])
+
+	apply(t, "[10:05:00.000] 10 1")
+
+	lap := next(t, reader)
+	assert.Equal(t, "2", lap.id)
+	assert.Equal(t, "lap", lap.event)
+	assert.EqualValues(t, 10, lap.data["eventId"])
+
+	finished := next(t, reader)
+	assert.Equal(t, "3", finished.id)
+	assert.Equal(t, "finished", finished.event)
+	competitor := finished.data["competitor"].(map[string]any)
+	assert.Equal(t, string(model.CompetitorStarted), competitor["status"])
+	assert.EqualValues(t, 300000, competitor["totalTimeMs"])
+	closeStream()
+
+	t.Run("Resume", func(t *testing.T) {
+		reader, closeStream := subscribe(t, "2")
+		defer closeStream()
+
+		resumed := next(t, reader)
+		assert.Equal(t, "3", resumed.id)
+		assert.Equal(t, "finished", resumed.event)
+	})
+
+	t.Run("InvalidSequence", func(t *testing.T) {
+		resp, err := http.Get(ts.URL + "/stream?since=last")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+	t.Run("ClosedOnShutdown", func(t *testing.T) {
+		streamSrv := server.New(config, timeFormat, 5, false)
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		httpServer := &http.Server{Handler: streamSrv.Handler()}
+		httpServer.RegisterOnShutdown(streamSrv.Close)
+		go httpServer.Serve(listener)
+
+		resp, err := http.Get("http://" + listener.Addr().String() + "/stream")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		require.NoError(t, httpServer.Shutdown(ctx))
+
+		_, err = io.ReadAll(resp.Body)
+		assert.NoError(t, err, "the stream ends cleanly")
+	})
+}
@@ -0,0 +1,122 @@
+package _test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Maksim646/sunny_5_skiers/internal/controller"
+	"github.com/Maksim646/sunny_5_skiers/internal/race"
+	"github.com/Maksim646/sunny_5_skiers/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeline(t *testing.T) {
+	timeFormat := "15:04:05.000"
+
+	t.Run("DatedConfig", func(t *testing.T) {
+		config, err := controller.ParseConfig("test_config/test_config_dated.json", timeFormat, "15:04:05")
+		require.NoError(t, err)
+
+		oslo, err := time.LoadLocation("Europe/Oslo")
+		require.NoError(t, err)
+		assert.True(t, config.Date.Equal(time.Date(2025, time.May, 6, 0, 0, 0, 0, oslo)))
+		assert.Equal(t, "Europe/Oslo", config.Date.Location().String())
+	})
+
+	t.Run("InvalidDate", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.json")
+		content := `{"start": "10:00:00.000", "startDelta": "00:01:00", "date": "06.05.2025"}`
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+		_, err := controller.ParseConfig(path, timeFormat, "15:04:05")
+		assert.EqualError(t, err, `invalid race date "06.05.2025"`)
+	})
+
+	t.Run("ExtendedTimestamp", func(t *testing.T) {
+		withOffset, err := controller.ParseEventTime("2025-05-06T23:59:00.500+02:00", timeFormat)
+		require.NoError(t, err)
+		assert.True(t, withOffset.Equal(time.Date(2025, time.May, 6, 21, 59, 0, 500e6, time.UTC)))
+
+		withoutOffset, err := controller.ParseEventTime("2025-05-06T23:59:00.500", timeFormat)
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2025, time.May, 6, 23, 59, 0, 500e6, time.UTC), withoutOffset)
+
+		_, err = controller.ParseEventTime("2025-05-06T23:59", timeFormat)
+		assert.Error(t, err)
+	})
+
+	t.Run("MidnightRollover", func(t *testing.T) {
+		date := time.Date(2025, time.May, 6, 0, 0, 0, 0, time.UTC)
+		events, err := controller.ReadEvents(strings.NewReader(
+			"[23:58:00.000] 1 1\n"+
+				"[23:59:59.000] 4 1\n"+
+				"[23:59:58.000] 5 1 1\n"+
+				"[00:00:10.000] 7 1\n"+
+				"[2025-05-08T09:00:00.000] 1 2\n"+
+				"[09:00:01.000] 1 3\n",
+		), "events", timeFormat)
+		require.NoError(t, err)
+
+		placed := controller.PlaceEvents(events, date)
+		assert.Equal(t, time.Date(2025, time.May, 6, 23, 58, 0, 0, time.UTC), placed[0].Time)
+		// A small step back is not a new day, the validator rejects it.
+		assert.Equal(t, time.Date(2025, time.May, 6, 23, 59, 58, 0, time.UTC), placed[2].Time)
+		assert.Equal(t, time.Date(2025, time.May, 7, 0, 0, 10, 0, time.UTC), placed[3].Time)
+		assert.Equal(t, time.Date(2025, time.May, 8, 9, 0, 0, 0, time.UTC), placed[4].Time)
+		assert.Equal(t, time.Date(2025, time.May, 8, 9, 0, 1, 0, time.UTC), placed[5].Time)
+	})
+
+	t.Run("RaceTimeZone", func(t *testing.T) {
+		oslo, err := time.LoadLocation("Europe/Oslo")
+		require.NoError(t, err)
+		timeline := controller.NewTimeline(time.Date(2025, time.May, 6, 0, 0, 0, 0, oslo))
+
+		clock, err := controller.ParseEventTime("10:00:00.000", timeFormat)
+		require.NoError(t, err)
+		event := timeline.Place(model.CompetitorEvent{Time: clock, ID: 1, Competitor: 1})
+		assert.True(t, event.Time.Equal(time.Date(2025, time.May, 6, 8, 0, 0, 0, time.UTC)))
+
+		dated, err := controller.ParseEventTime("2025-05-07T10:00:00.000", timeFormat)
+		require.NoError(t, err)
+		event = timeline.Place(model.CompetitorEvent{Time: dated, ID: 1, Competitor: 2})
+		assert.True(t, event.Time.Equal(time.Date(2025, time.May, 7, 8, 0, 0, 0, time.UTC)))
+
+		zulu, err := controller.ParseEventTime("2025-05-07T10:00:01.000Z", timeFormat)
+		require.NoError(t, err)
+		event = timeline.Place(model.CompetitorEvent{Time: zulu, ID: 1, Competitor: 3})
+		assert.True(t, event.Time.Equal(time.Date(2025, time.May, 7, 10, 0, 1, 0, time.UTC)), "Z is UTC, not the race time zone")
+	})
+
+	t.Run("RaceAcrossMidnight", func(t *testing.T) {
+		config, err := controller.ParseConfig("test_config/test_config_dated.json", timeFormat, "15:04:05")
+		require.NoError(t, err)
+
+		events, err := controller.ReadEvents(strings.NewReader(
+			"[23:50:00.000] 1 1\n"+
+				"[23:55:00.000] 2 1 00:01:00.000\n"+
+				"[00:00:30.000] 3 1\n"+
+				"[00:01:00.000] 4 1\n"+
+				"[00:06:00.000] 10 1\n",
+		), "events", timeFormat)
+		require.NoError(t, err)
+		events = controller.PlaceEvents(events, config.Date)
+
+		require.NoError(t, controller.ValidateSequence(events, config, "15:04:05.000", 5, true))
+
+		engine := race.NewEngine(config, timeFormat, 5)
+		for _, event := range events {
+			_, err := engine.Apply(event)
+			require.NoError(t, err)
+		}
+		engine.Finish()
+
+		report, ok := engine.Report(1)
+		require.True(t, ok)
+		assert.Equal(t, model.CompetitorStarted, report.Status)
+		assert.Equal(t, 5*time.Minute, report.TotalTime)
+	})
+}
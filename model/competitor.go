@@ -2,12 +2,35 @@ package model
 
 import "time"
 
-var (
-	CompetitorStarted     = "started"
-	CompetitorNotStarted  = "NotStarted"
-	CompetitorNotFinished = "NotFinished"
+// Status is the state of a competitor in the result table.
+type Status string
+
+const (
+	CompetitorStarted      Status = "started"
+	CompetitorRunning      Status = "Running"
+	CompetitorNotStarted   Status = "NotStarted"
+	CompetitorNotFinished  Status = "NotFinished"
+	CompetitorDisqualified Status = "Disqualified"
+	CompetitorLapped       Status = "Lapped"
 )
 
+// Abbreviation returns the usual short form of the status, e.g. DNF.
+// Statuses without one are returned as is.
+func (s Status) Abbreviation() string {
+	switch s {
+	case CompetitorNotStarted:
+		return "DNS"
+	case CompetitorNotFinished:
+		return "DNF"
+	case CompetitorDisqualified:
+		return "DSQ"
+	case CompetitorLapped:
+		return "LAP"
+	default:
+		return string(s)
+	}
+}
+
 const (
 	EventRegistered       = 1
 	EventStartTimeSet     = 2
@@ -20,6 +43,11 @@ const (
 	EventPenaltyLapEnd    = 9
 	EventLapCompleted     = 10
 	EventNotFinished      = 11
+	EventJuryDisqualified = 12
+	EventLapped           = 13
+
+	EventDisqualified = 32
+	EventFinished     = 33
 )
 
 var (
@@ -35,9 +63,18 @@ var (
 		9:  "left the penalty laps",
 		10: "ended the main lap",
 		11: "can`t continue",
+		12: "is disqualified by the jury",
+		13: "is lapped",
+		32: "is disqualified",
+		33: "has finished",
 	}
 )
 
+const (
+	PositionProne    = "prone"
+	PositionStanding = "standing"
+)
+
 type CompetitorEvent struct {
 	Time        time.Time
 	ID          int
@@ -48,14 +85,61 @@ type CompetitorEvent struct {
 type LapInfo struct {
 	Time  time.Duration
 	Speed float64
+	// Split is the time from the start to the end of the lap and SplitRank
+	// its rank among every competitor who completed the lap. Both are only
+	// set for main laps.
+	Split     time.Duration
+	SplitRank int
+}
+
+// FiringRangeVisit is a single stay of a competitor on a firing range,
+// from event 5 to event 7.
+type FiringRangeVisit struct {
+	FiringRange int
+	Position    string
+	Targets     int
+	HitTargets  []int
+	Misses      int
+	Time        time.Duration
 }
 
 type CompetitorReport struct {
 	CompetitorID int
-	Status       string
+	// Athlete is the roster entry of the competitor, zero when there is none.
+	Athlete  Athlete
+	Category string
+	// Place and CategoryPlace rank the competitors with a result, overall
+	// and within Category. Both are 0 for competitors without a result.
+	Place         int
+	CategoryPlace int
+	// GapToLeader and GapToAhead are the time behind the winner and behind
+	// the previous competitor with a result, 0 for the winner and for
+	// competitors without a result.
+	GapToLeader time.Duration
+	GapToAhead  time.Duration
+	Status      Status
+	// Reason explains the status: the comment of event 11, the jury decision
+	// of event 12 or a missed start.
+	Reason       string
 	TotalTime    time.Duration
+	StartLag     time.Duration
 	Laps         []LapInfo
-	PenaltyLaps  []LapInfo
+	FiringRanges []FiringRangeVisit
 	Hits         int
 	Shots        int
+	// TimePenalty is the time added to TotalTime for misses in formats
+	// without penalty loops.
+	TimePenalty time.Duration
+
+	// PenaltyLaps holds every stay in the penalty laps, from event 8 to event 9.
+	PenaltyLaps []LapInfo
+	// PenaltyLoops is the number of loops required by the misses.
+	PenaltyLoops int
+	// PenaltyLoopsRun is the number of loops covered by the stays.
+	PenaltyLoopsRun int
+	// MissedPenaltyLoops is the number of required loops that were never run.
+	MissedPenaltyLoops int
+	PenaltyTime        time.Duration
+	PenaltyDistance    int
+	PenaltySpeed       float64
 }
@@ -2,15 +2,57 @@ package model
 
 import "time"
 
+const (
+	FormatSprint     = "sprint"
+	FormatIndividual = "individual"
+	FormatPursuit    = "pursuit"
+	FormatMassStart  = "massStart"
+)
+
+var Formats = []string{FormatSprint, FormatIndividual, FormatPursuit, FormatMassStart}
+
 type Config struct {
-	Laps        int `json:"laps"`
-	LapLen      int `json:"lapLen"`
-	PenaltyLen  int `json:"penaltyLen"`
-	FiringLines int `json:"firingLines"`
+	Laps        int    `json:"laps"`
+	LapLen      int    `json:"lapLen"`
+	PenaltyLen  int    `json:"penaltyLen"`
+	FiringLines int    `json:"firingLines"`
+	Format      string `json:"format"`
+	// MaxPenaltySpeed is the fastest a penalty loop can be skied in m/s, a
+	// shorter stay counts fewer loops. 0 counts every owed loop.
+	MaxPenaltySpeed float64 `json:"maxPenaltySpeed"`
+	// Categories maps a category to its competitor IDs, for competitors
+	// whose category is not on the roster.
+	Categories map[string][]int `json:"categories"`
+
+	StartRaw       string `json:"start"`
+	DeltaRaw       string `json:"startDelta"`
+	PenaltyTimeRaw string `json:"penaltyTime"`
+	// The resolutions are Go durations such as "100ms".
+	InputResolutionRaw   string `json:"inputResolution"`
+	RankingResolutionRaw string `json:"rankingResolution"`
+	DisplayResolutionRaw string `json:"displayResolution"`
+	// Rounding brings times to the resolutions, truncate by default.
+	Rounding Rounding `json:"rounding"`
+	// DateRaw is the first day of the race, e.g. "2025-05-06", in the IANA
+	// time zone TimeZoneRaw, UTC when empty.
+	DateRaw     string `json:"date"`
+	TimeZoneRaw string `json:"timeZone"`
 
-	StartRaw string `json:"start"`
-	DeltaRaw string `json:"startDelta"`
+	Start       time.Time     `json:"-"`
+	StartDelta  time.Duration `json:"-"`
+	PenaltyTime time.Duration `json:"-"`
+	// InputResolution is the precision event times are recorded at.
+	InputResolution time.Duration `json:"-"`
+	// RankingResolution is the precision times are compared at, equal times
+	// share a place.
+	RankingResolution time.Duration `json:"-"`
+	// DisplayResolution is the precision of the times in the result table.
+	// A resolution of 0 keeps the full precision. Speeds are not affected.
+	DisplayResolution time.Duration `json:"-"`
+	// Date is the midnight the clock times of the events are placed on. It
+	// is 0000-01-01 in the race time zone when no date is configured.
+	Date time.Time `json:"-"`
 
-	Start      time.Time     `json:"-"`
-	StartDelta time.Duration `json:"-"`
+	// Roster is loaded from a separate file, a nil roster accepts every competitor.
+	Roster Roster `json:"-"`
 }
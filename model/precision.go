@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+// Rounding is how times are brought to a resolution.
+type Rounding string
+
+const (
+	RoundingTruncate Rounding = "truncate"
+	RoundingRound    Rounding = "round"
+)
+
+var Roundings = []Rounding{RoundingTruncate, RoundingRound}
+
+// Duration returns d at resolution, a resolution of 0 keeps d as is.
+func (r Rounding) Duration(d time.Duration, resolution time.Duration) time.Duration {
+	if resolution <= 0 {
+		return d
+	}
+	if r == RoundingRound {
+		return d.Round(resolution)
+	}
+	return d.Truncate(resolution)
+}
+
+// Time returns t at resolution, a resolution of 0 keeps t as is.
+func (r Rounding) Time(t time.Time, resolution time.Duration) time.Time {
+	if resolution <= 0 {
+		return t
+	}
+	if r == RoundingRound {
+		return t.Round(resolution)
+	}
+	return t.Truncate(resolution)
+}
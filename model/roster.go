@@ -0,0 +1,15 @@
+package model
+
+// Athlete is a roster entry. ID is the competitor ID used by the events.
+type Athlete struct {
+	ID       int    `json:"id"`
+	Bib      int    `json:"bib"`
+	Name     string `json:"name"`
+	Nation   string `json:"nation"`
+	Club     string `json:"club"`
+	Category string `json:"category"`
+	Gender   string `json:"gender"`
+}
+
+// Roster maps competitor IDs to athletes.
+type Roster map[int]Athlete